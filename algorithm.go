@@ -2,6 +2,10 @@ package itsdangerous
 
 import (
 	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
 	"hash"
 )
 
@@ -30,3 +34,21 @@ func (a *HMACAlgorithm) VerifySignature(key []byte, value string, signature []by
 		a.GetSignature(key, value),
 	)
 }
+
+// DigestByName returns the digest for one of the names "sha1", "sha224",
+// "sha256", "sha384" or "sha512".
+func DigestByName(name string) (func() hash.Hash, error) {
+	switch name {
+	case "sha1":
+		return sha1.New, nil
+	case "sha224":
+		return sha256.New224, nil
+	case "sha256":
+		return sha256.New, nil
+	case "sha384":
+		return sha512.New384, nil
+	case "sha512":
+		return sha512.New, nil
+	}
+	return nil, errors.New("unknown digest " + name)
+}
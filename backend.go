@@ -0,0 +1,218 @@
+package itsdangerous
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SigningBackend provides interfaces to generate and verify signatures
+// without exposing the key, eg by calling out to a key management service or
+// HSM. Unlike SigningAlgorithm, calls take a context and may fail.
+type SigningBackend interface {
+	Sign(ctx context.Context, value string) ([]byte, error)
+	Verify(ctx context.Context, value string, signature []byte) (bool, error)
+}
+
+// LocalBackend adapts a key and SigningAlgorithm onto SigningBackend, signing
+// in process. It is what NewSigner uses, and can stand in for a remote backend
+// in tests.
+type LocalBackend struct {
+	Key []byte
+	// Algorithm defaults to HMAC-SHA1, as with NewSigner, if nil.
+	Algorithm SigningAlgorithm
+}
+
+// Sign returns the signature for the given value.
+func (b *LocalBackend) Sign(ctx context.Context, value string) ([]byte, error) {
+	return b.algorithm().GetSignature(b.Key, value), nil
+}
+
+// Verify verifies the given signature matches the expected signature.
+func (b *LocalBackend) Verify(ctx context.Context, value string, signature []byte) (bool, error) {
+	return b.algorithm().VerifySignature(b.Key, value, signature), nil
+}
+
+func (b *LocalBackend) algorithm() SigningAlgorithm {
+	if b.Algorithm == nil {
+		return &HMACAlgorithm{DigestMethod: sha1.New}
+	}
+	return b.Algorithm
+}
+
+// bind returns a backend whose key is derived from this one and the given
+// data.
+func (b *LocalBackend) bind(data string) SigningBackend {
+	return &LocalBackend{
+		Key:       b.algorithm().GetSignature(b.Key, data),
+		Algorithm: b.Algorithm,
+	}
+}
+
+// binder is implemented by backends which can derive a sub-key, as required
+// to bind signatures to extra data.
+type binder interface {
+	bind(data string) SigningBackend
+}
+
+// checkSep returns the separator, defaulting to ".", or an error if it may be
+// contained in a signature.
+func checkSep(sep string) (string, error) {
+	if sep == "" {
+		sep = "."
+	}
+	if strings.Trim(sep, base64Alphabet) == "" {
+		return "", fmt.Errorf("separator %q cannot be used because it may be contained in the signature itself; ASCII letters, digits, and '-_=' must not be used", sep)
+	}
+	return sep, nil
+}
+
+// BackendSigner works like Signer but signs with a SigningBackend which may
+// fail, such as a remote key service or a Keyring, which fails once none of
+// its keys is active. It only has the methods which take a context and
+// return an error, so a failing backend can't cause a panic.
+type BackendSigner struct {
+	sep     string
+	backend SigningBackend
+
+	// RevocationChecker works as for Signer.
+	RevocationChecker RevocationChecker
+}
+
+// NewSignerWithBackend creates a new BackendSigner which signs using the
+// given backend.
+func NewSignerWithBackend(backend SigningBackend, sep string) (*BackendSigner, error) {
+	sep, err := checkSep(sep)
+	if err != nil {
+		return nil, err
+	}
+	return &BackendSigner{sep: sep, backend: backend}, nil
+}
+
+func (s *BackendSigner) signer() *Signer {
+	return &Signer{sep: s.sep, backend: s.backend, RevocationChecker: s.RevocationChecker}
+}
+
+// SignContext signs the given string, passing ctx to the signing backend.
+func (s *BackendSigner) SignContext(ctx context.Context, value string) (string, error) {
+	return s.signer().SignContext(ctx, value)
+}
+
+// UnsignContext unsigns the given string, passing ctx to the signing backend.
+func (s *BackendSigner) UnsignContext(ctx context.Context, signed string) (string, error) {
+	return s.signer().UnsignContext(ctx, signed)
+}
+
+// UnsignWithKeyID works like UnsignContext but also returns the ID of the key
+// which verified the signature. The ID is empty unless the signer uses a
+// Keyring.
+func (s *BackendSigner) UnsignWithKeyID(ctx context.Context, signed string) (string, string, error) {
+	value, key, err := s.signer().unsign(ctx, signed)
+	if err != nil || key == nil {
+		return value, "", err
+	}
+	return value, key.ID, nil
+}
+
+// SignDetachedContext returns only the signature for the given string. See
+// Signer.SignDetached.
+func (s *BackendSigner) SignDetachedContext(ctx context.Context, value string) (string, error) {
+	return s.signer().SignDetachedContext(ctx, value)
+}
+
+// VerifyDetachedContext verifies a signature produced by SignDetachedContext
+// for the given string.
+func (s *BackendSigner) VerifyDetachedContext(ctx context.Context, value, sig string) error {
+	return s.signer().VerifyDetachedContext(ctx, value, sig)
+}
+
+// Fingerprint returns an identifier of the signer's key. See
+// Signer.Fingerprint.
+func (s *BackendSigner) Fingerprint() string {
+	return s.signer().Fingerprint()
+}
+
+// BackendTimestampSigner works like TimestampSigner but signs with a
+// SigningBackend which may fail. As with BackendSigner, it only has the
+// methods which take a context and return an error.
+type BackendTimestampSigner struct {
+	sep     string
+	backend SigningBackend
+
+	// RevocationChecker and MillisecondTimestamps work as for
+	// TimestampSigner.
+	RevocationChecker     RevocationChecker
+	MillisecondTimestamps bool
+}
+
+// NewTimestampSignerWithBackend creates a new BackendTimestampSigner which
+// signs using the given backend.
+func NewTimestampSignerWithBackend(backend SigningBackend, sep string) (*BackendTimestampSigner, error) {
+	sep, err := checkSep(sep)
+	if err != nil {
+		return nil, err
+	}
+	return &BackendTimestampSigner{sep: sep, backend: backend}, nil
+}
+
+func (s *BackendTimestampSigner) signer() *TimestampSigner {
+	return &TimestampSigner{
+		Signer:                Signer{sep: s.sep, backend: s.backend, RevocationChecker: s.RevocationChecker},
+		MillisecondTimestamps: s.MillisecondTimestamps,
+	}
+}
+
+// SignContext signs the given string, passing ctx to the signing backend.
+func (s *BackendTimestampSigner) SignContext(ctx context.Context, value string) (string, error) {
+	return s.signer().SignContext(ctx, value)
+}
+
+// SignWithExpiryContext works like SignContext but also records that the
+// signature expires ttl after signing. See TimestampSigner.SignWithExpiry.
+func (s *BackendTimestampSigner) SignWithExpiryContext(ctx context.Context, value string, ttl time.Duration) (string, error) {
+	return s.signer().SignWithExpiryContext(ctx, value, ttl)
+}
+
+// SignWithNotBeforeContext works like SignContext but also records that the
+// signature is not valid until notBefore. See
+// TimestampSigner.SignWithNotBefore.
+func (s *BackendTimestampSigner) SignWithNotBeforeContext(ctx context.Context, value string, notBefore time.Time, ttl time.Duration) (string, error) {
+	return s.signer().SignWithNotBeforeContext(ctx, value, notBefore, ttl)
+}
+
+// UnsignContext unsigns the given string, passing ctx to the signing backend.
+// See TimestampSigner.Unsign.
+func (s *BackendTimestampSigner) UnsignContext(ctx context.Context, value string, maxAge time.Duration) (string, error) {
+	return s.signer().UnsignContext(ctx, value, maxAge)
+}
+
+// UnsignWithKeyID works like UnsignContext but also returns the ID of the key
+// which verified the signature. The ID is empty unless the signer uses a
+// Keyring.
+func (s *BackendTimestampSigner) UnsignWithKeyID(ctx context.Context, value string, maxAge time.Duration) (string, string, error) {
+	val, _, key, err := s.signer().unsign(ctx, value, maxAge)
+	if err != nil || key == nil {
+		return val, "", err
+	}
+	return val, key.ID, nil
+}
+
+// SignDetachedContext returns the timestamp and signature for the given
+// string. See TimestampSigner.SignDetached.
+func (s *BackendTimestampSigner) SignDetachedContext(ctx context.Context, value string) (string, error) {
+	return s.signer().SignDetachedContext(ctx, value)
+}
+
+// VerifyDetachedContext verifies a timestamp and signature produced by
+// SignDetachedContext for the given string.
+func (s *BackendTimestampSigner) VerifyDetachedContext(ctx context.Context, value, sig string, maxAge time.Duration) error {
+	return s.signer().VerifyDetachedContext(ctx, value, sig, maxAge)
+}
+
+// Fingerprint returns an identifier of the signer's key. See
+// Signer.Fingerprint.
+func (s *BackendTimestampSigner) Fingerprint() string {
+	return s.signer().Fingerprint()
+}
@@ -0,0 +1,252 @@
+package itsdangerous_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+// remoteBackend stands in for a remote key service, recording the context it
+// was called with and optionally failing.
+type remoteBackend struct {
+	local itsdangerous.LocalBackend
+	err   error
+	ctx   context.Context
+}
+
+func (b *remoteBackend) Sign(ctx context.Context, value string) ([]byte, error) {
+	b.ctx = ctx
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.local.Sign(ctx, value)
+}
+
+func (b *remoteBackend) Verify(ctx context.Context, value string, signature []byte) (bool, error) {
+	b.ctx = ctx
+	if b.err != nil {
+		return false, b.err
+	}
+	return b.local.Verify(ctx, value, signature)
+}
+
+// signerKey is the key NewSigner("secret_key", "salt") derives.
+func signerKey() []byte {
+	h := sha1.New()
+	h.Write([]byte("salt" + "signer" + "secret_key"))
+	return h.Sum(nil)
+}
+
+func TestLocalBackend(t *testing.T) {
+	sig, err := itsdangerous.NewSignerWithBackend(&itsdangerous.LocalBackend{Key: signerKey()}, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithBackend returned error: %s", err)
+	}
+
+	const expected = "my string.xv0r21ogoygusbkJA01c4OxsAio"
+	if actual, err := sig.SignContext(context.Background(), "my string"); err != nil || actual != expected {
+		t.Errorf("SignContext(my string) got %s, %v; want %s", actual, err, expected)
+	}
+	if actual, err := sig.UnsignContext(context.Background(), expected); err != nil || actual != "my string" {
+		t.Errorf("UnsignContext(%s) got %s, %v; want my string", expected, actual, err)
+	}
+}
+
+type ctxKey struct{}
+
+func TestSigningBackendContext(t *testing.T) {
+	backend := &remoteBackend{local: itsdangerous.LocalBackend{Key: signerKey()}}
+	sig, err := itsdangerous.NewTimestampSignerWithBackend(backend, "")
+	if err != nil {
+		t.Fatalf("NewTimestampSignerWithBackend returned error: %s", err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	signed, err := sig.SignContext(ctx, "my string")
+	if err != nil {
+		t.Fatalf("SignContext returned error: %s", err)
+	}
+	if backend.ctx != ctx {
+		t.Errorf("SignContext did not pass context to backend")
+	}
+
+	backend.ctx = nil
+	actual, err := sig.UnsignContext(ctx, signed, 0)
+	if err != nil {
+		t.Fatalf("UnsignContext(%s) returned error: %s", signed, err)
+	}
+	if actual != "my string" {
+		t.Errorf("UnsignContext(%s) got %s; want my string", signed, actual)
+	}
+	if backend.ctx != ctx {
+		t.Errorf("UnsignContext did not pass context to backend")
+	}
+}
+
+func TestSigningBackendContextVariants(t *testing.T) {
+	backend := &remoteBackend{local: itsdangerous.LocalBackend{Key: signerKey()}}
+	plain, err := itsdangerous.NewSignerWithBackend(backend, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithBackend returned error: %s", err)
+	}
+	sig, err := itsdangerous.NewTimestampSignerWithBackend(backend, "")
+	if err != nil {
+		t.Fatalf("NewTimestampSignerWithBackend returned error: %s", err)
+	}
+	serializer, err := itsdangerous.NewURLSafeSerializerWithBackend(backend, "")
+	if err != nil {
+		t.Fatalf("NewURLSafeSerializerWithBackend returned error: %s", err)
+	}
+	timed, err := itsdangerous.NewURLSafeTimedSerializerWithBackend(backend, "")
+	if err != nil {
+		t.Fatalf("NewURLSafeTimedSerializerWithBackend returned error: %s", err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	var value string
+	detached, err := plain.SignDetachedContext(ctx, "my string")
+	if err != nil {
+		t.Fatalf("SignDetachedContext returned error: %s", err)
+	}
+	timestampDetached, err := sig.SignDetachedContext(ctx, "my string")
+	if err != nil {
+		t.Fatalf("SignDetachedContext returned error: %s", err)
+	}
+	marshalled, err := serializer.MarshalContext(ctx, "my string")
+	if err != nil {
+		t.Fatalf("MarshalContext returned error: %s", err)
+	}
+	timedMarshalled, err := timed.MarshalContext(ctx, "my string")
+	if err != nil {
+		t.Fatalf("MarshalContext returned error: %s", err)
+	}
+
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{name: "BackendSigner.SignContext", call: func(ctx context.Context) error {
+			_, err := plain.SignContext(ctx, "my string")
+			return err
+		}},
+		{name: "BackendSigner.SignDetachedContext", call: func(ctx context.Context) error {
+			_, err := plain.SignDetachedContext(ctx, "my string")
+			return err
+		}},
+		{name: "BackendSigner.VerifyDetachedContext", call: func(ctx context.Context) error {
+			return plain.VerifyDetachedContext(ctx, "my string", detached)
+		}},
+		{name: "SignDetachedContext", call: func(ctx context.Context) error {
+			_, err := sig.SignDetachedContext(ctx, "my string")
+			return err
+		}},
+		{name: "VerifyDetachedContext", call: func(ctx context.Context) error {
+			return sig.VerifyDetachedContext(ctx, "my string", timestampDetached, 0)
+		}},
+		{name: "SignWithExpiryContext", call: func(ctx context.Context) error {
+			_, err := sig.SignWithExpiryContext(ctx, "my string", time.Hour)
+			return err
+		}},
+		{name: "SignWithNotBeforeContext", call: func(ctx context.Context) error {
+			_, err := sig.SignWithNotBeforeContext(ctx, "my string", time.Now(), 0)
+			return err
+		}},
+		{name: "URLSafeSerializer.MarshalContext", call: func(ctx context.Context) error {
+			_, err := serializer.MarshalContext(ctx, "my string")
+			return err
+		}},
+		{name: "URLSafeSerializer.UnmarshalContext", call: func(ctx context.Context) error {
+			return serializer.UnmarshalContext(ctx, marshalled, &value)
+		}},
+		{name: "MarshalContext", call: func(ctx context.Context) error {
+			_, err := timed.MarshalContext(ctx, "my string")
+			return err
+		}},
+		{name: "UnmarshalContext", call: func(ctx context.Context) error {
+			return timed.UnmarshalContext(ctx, timedMarshalled, &value, 0)
+		}},
+		{name: "MarshalWithExpiryContext", call: func(ctx context.Context) error {
+			_, err := timed.MarshalWithExpiryContext(ctx, "my string", time.Hour)
+			return err
+		}},
+		{name: "MarshalWithNotBeforeContext", call: func(ctx context.Context) error {
+			_, err := timed.MarshalWithNotBeforeContext(ctx, "my string", time.Now(), 0)
+			return err
+		}},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			backend.ctx, backend.err = nil, nil
+			if err := test.call(ctx); err != nil {
+				t.Fatalf("%s returned error: %s", test.name, err)
+			}
+			if backend.ctx != ctx {
+				t.Errorf("%s did not pass context to backend", test.name)
+			}
+
+			// Backend failures are returned rather than panicking.
+			backend.err = errors.New("key service unavailable")
+			defer func() { backend.err = nil }()
+			if err := test.call(ctx); !errors.Is(err, backend.err) {
+				t.Errorf("%s expected backend error; got %v", test.name, err)
+			}
+		})
+	}
+}
+
+func TestSigningBackendError(t *testing.T) {
+	backendErr := errors.New("key service unavailable")
+	backend := &remoteBackend{err: backendErr}
+	sig, err := itsdangerous.NewSignerWithBackend(backend, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithBackend returned error: %s", err)
+	}
+
+	if _, err := sig.SignContext(context.Background(), "my string"); !errors.Is(err, backendErr) {
+		t.Errorf("SignContext expected backend error; got %v", err)
+	}
+
+	_, err = sig.UnsignContext(context.Background(), "my string.xv0r21ogoygusbkJA01c4OxsAio")
+	if !errors.Is(err, backendErr) {
+		t.Fatalf("UnsignContext expected backend error; got %v", err)
+	}
+	if errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+		t.Errorf("UnsignContext backend error should not be an InvalidSignatureError")
+	}
+
+	s, err := itsdangerous.NewURLSafeSerializerWithBackend(backend, "")
+	if err != nil {
+		t.Fatalf("NewURLSafeSerializerWithBackend returned error: %s", err)
+	}
+	if _, err := s.MarshalContext(context.Background(), "my string"); !errors.Is(err, backendErr) {
+		t.Errorf("MarshalContext expected backend error; got %v", err)
+	}
+}
+
+func TestSigningBackendBinding(t *testing.T) {
+	s, err := itsdangerous.NewURLSafeTimedSerializerWithBackend(&remoteBackend{}, "")
+	if err != nil {
+		t.Fatalf("NewURLSafeTimedSerializerWithBackend returned error: %s", err)
+	}
+
+	if _, err := s.MarshalBoundContext(context.Background(), "my string", "binding"); err == nil {
+		t.Errorf("MarshalBoundContext expected error for backend without binding support")
+	}
+}
+
+// signContext signs the value with the given signer's SignContext, failing
+// the test if signing fails.
+func signContext(t *testing.T, s interface {
+	SignContext(ctx context.Context, value string) (string, error)
+}, value string) string {
+	t.Helper()
+	signed, err := s.SignContext(context.Background(), value)
+	if err != nil {
+		t.Fatalf("SignContext returned error: %s", err)
+	}
+	return signed
+}
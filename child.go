@@ -0,0 +1,165 @@
+package itsdangerous
+
+import (
+	"container/list"
+	"errors"
+	"hash"
+	"sync"
+)
+
+// signerSecret is what a Signer created with NewParentSigner needs to derive
+// child signers with other salts.
+type signerSecret struct {
+	secret     string
+	derivation KeyDerivation
+	digest     func() hash.Hash
+	algo       SigningAlgorithm
+}
+
+// ChildCache is a bounded, least recently used cache of the signing keys of
+// child signers created by WithSalt, so that creating a signer per tenant or
+// per request doesn't derive the key every time. It is safe for concurrent
+// use, and may be shared by many signers.
+type ChildCache struct {
+	mu      sync.Mutex
+	size    int
+	entries *list.List
+	items   map[childCacheKey]*list.Element
+}
+
+type childCacheKey struct {
+	parent *signerSecret
+	salt   string
+}
+
+type childCacheEntry struct {
+	key     childCacheKey
+	backend SigningBackend
+}
+
+// NewChildCache creates a new ChildCache holding at most size keys.
+func NewChildCache(size int) *ChildCache {
+	return &ChildCache{size: size, entries: list.New(), items: make(map[childCacheKey]*list.Element)}
+}
+
+func (c *ChildCache) get(key childCacheKey) (SigningBackend, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.entries.MoveToFront(e)
+	return e.Value.(*childCacheEntry).backend, true
+}
+
+func (c *ChildCache) add(key childCacheKey, backend SigningBackend) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		c.entries.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.entries.PushFront(&childCacheEntry{key: key, backend: backend})
+	for c.entries.Len() > c.size {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.items, oldest.Value.(*childCacheEntry).key)
+	}
+}
+
+// Len returns the number of keys in the cache.
+func (c *ChildCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+// WithSalt returns a copy of the signer using the given salt in place of its
+// own, keeping the separator, key derivation, digest, signing algorithm and
+// revocation checker. As with NewSigner an empty salt means
+// "itsdangerous.Signer". It is typically used to namespace tokens by tenant,
+// so a token issued for one tenant is not valid for another.
+//
+// If ChildCache is set the child's key is cached there. WithSalt returns an
+// error if the signer was not created with NewParentSigner, or one of the
+// other NewParent constructors, as other signers don't keep their secret. The child doesn't keep it either, so it can't
+// create children of its own.
+func (s *Signer) WithSalt(salt string) (*Signer, error) {
+	if s.secret == nil {
+		return nil, errors.New("WithSalt requires a signer created with a NewParent constructor")
+	}
+	child := *s
+	child.secret = nil
+	if salt == "" {
+		salt = "itsdangerous.Signer"
+	}
+
+	key := childCacheKey{parent: s.secret, salt: salt}
+	if s.ChildCache != nil {
+		if backend, ok := s.ChildCache.get(key); ok {
+			child.backend = backend
+			return &child, nil
+		}
+	}
+	derived, err := s.secret.derivation.DeriveKey(s.secret.secret, salt, s.secret.digest)
+	if err != nil {
+		return nil, err
+	}
+	child.backend = &LocalBackend{Key: derived, Algorithm: s.secret.algo}
+	if s.ChildCache != nil {
+		s.ChildCache.add(key, child.backend)
+	}
+	return &child, nil
+}
+
+// WithSalt returns a copy of the signer using the given salt in place of its
+// own. See Signer.WithSalt.
+func (s *TimestampSigner) WithSalt(salt string) (*TimestampSigner, error) {
+	signer, err := s.Signer.WithSalt(salt)
+	if err != nil {
+		return nil, err
+	}
+	child := *s
+	child.Signer = *signer
+	return &child, nil
+}
+
+// WithSalt returns a copy of the serializer using the given salt in place of
+// its own. See Signer.WithSalt.
+func (s *URLSafeSerializer) WithSalt(salt string) (*URLSafeSerializer, error) {
+	signer, err := s.Signer.WithSalt(salt)
+	if err != nil {
+		return nil, err
+	}
+	child := *s
+	child.Signer = *signer
+	return &child, nil
+}
+
+// WithSalt returns a copy of the serializer using the given salt in place of
+// its own, keeping its Purpose. See Signer.WithSalt.
+func (s *URLSafeTimedSerializer) WithSalt(salt string) (*URLSafeTimedSerializer, error) {
+	signer, err := s.TimestampSigner.WithSalt(salt)
+	if err != nil {
+		return nil, err
+	}
+	child := *s
+	child.TimestampSigner = *signer
+	return &child, nil
+}
+
+// WithSalt returns a copy of the serializer using the given salt in place of
+// its own, sharing its ReplayStore. See Signer.WithSalt.
+func (s *OneTimeSerializer) WithSalt(salt string) (*OneTimeSerializer, error) {
+	serializer, err := s.serializer.WithSalt(salt)
+	if err != nil {
+		return nil, err
+	}
+	child := *s
+	child.serializer = *serializer
+	return &child, nil
+}
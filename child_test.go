@@ -0,0 +1,199 @@
+package itsdangerous_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestWithSalt(t *testing.T) {
+	parent, err := itsdangerous.NewParentSigner("secret_key", "parent", "~", itsdangerous.HMACDerivation{}, sha256.New, nil)
+	if err != nil {
+		t.Fatalf("NewParentSigner returned error: %s", err)
+	}
+	expected, err := itsdangerous.NewSignerWithOptions("secret_key", "tenant", "~", "hmac", sha256.New, nil)
+	if err != nil {
+		t.Fatalf("NewSignerWithOptions returned error: %s", err)
+	}
+
+	tests := []struct {
+		name  string
+		cache *itsdangerous.ChildCache
+	}{
+		{name: "uncached"},
+		{name: "cached", cache: itsdangerous.NewChildCache(10)},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			parent.ChildCache = test.cache
+			// Twice, so the second child comes from the cache if set.
+			for i := 0; i < 2; i++ {
+				child, err := parent.WithSalt("tenant")
+				if err != nil {
+					t.Fatalf("WithSalt returned error: %s", err)
+				}
+				if actual, want := child.Sign("my string"), expected.Sign("my string"); actual != want {
+					t.Errorf("child Sign got %s; want %s", actual, want)
+				}
+				if _, err := parent.Unsign(child.Sign("my string")); err == nil {
+					t.Errorf("parent Unsign of child signature succeeded; want error")
+				}
+			}
+			if test.cache != nil && test.cache.Len() != 1 {
+				t.Errorf("ChildCache Len() got %d; want 1", test.cache.Len())
+			}
+		})
+	}
+
+	child, err := newParentSigner(t, "secret_key", "other").WithSalt("")
+	if err != nil {
+		t.Fatalf("WithSalt returned error: %s", err)
+	}
+	if actual, want := child.Sign("my string"), itsdangerous.NewSigner("secret_key", "").Sign("my string"); actual != want {
+		t.Errorf("WithSalt(\"\") Sign got %s; want %s", actual, want)
+	}
+	if _, err := child.WithSalt("other"); err == nil {
+		t.Errorf("child WithSalt returned no error")
+	}
+}
+
+// newParentSigner creates a Signer with default options which can create
+// children with WithSalt.
+func newParentSigner(t *testing.T, secret, salt string) *itsdangerous.Signer {
+	t.Helper()
+	s, err := itsdangerous.NewParentSigner(secret, salt, "", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewParentSigner returned error: %s", err)
+	}
+	return s
+}
+
+func TestWithSaltVariants(t *testing.T) {
+	parentTimestampSigner, err := itsdangerous.NewParentTimestampSigner("secret_key", "parent", "", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewParentTimestampSigner returned error: %s", err)
+	}
+	timestampSigner, err := parentTimestampSigner.WithSalt("salt")
+	if err != nil {
+		t.Fatalf("TimestampSigner WithSalt returned error: %s", err)
+	}
+	if _, err := itsdangerous.NewTimestampSigner("secret_key", "salt").Unsign(timestampSigner.Sign("my string"), 0); err != nil {
+		t.Errorf("TimestampSigner child signature failed to verify: %s", err)
+	}
+
+	parentSerializer, err := itsdangerous.NewParentURLSafeSerializer("secret_key", "parent", "", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewParentURLSafeSerializer returned error: %s", err)
+	}
+	serializer, err := parentSerializer.WithSalt("salt")
+	if err != nil {
+		t.Fatalf("URLSafeSerializer WithSalt returned error: %s", err)
+	}
+	signed, err := serializer.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	var value string
+	if err := itsdangerous.NewURLSafeSerializer("secret_key", "salt").Unmarshal(signed, &value); err != nil || value != "my string" {
+		t.Errorf("URLSafeSerializer child token got %q, %v; want my string", value, err)
+	}
+
+	timed, err := itsdangerous.NewParentURLSafeTimedSerializer("secret_key", "parent", "", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewParentURLSafeTimedSerializer returned error: %s", err)
+	}
+	timed.Purpose = "login"
+	timedChild, err := timed.WithSalt("salt")
+	if err != nil {
+		t.Fatalf("URLSafeTimedSerializer WithSalt returned error: %s", err)
+	}
+	if timedChild.Purpose != "login" {
+		t.Errorf("child Purpose got %q; want login", timedChild.Purpose)
+	}
+	signed, err = timedChild.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	expected := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+	expected.Purpose = "login"
+	if err := expected.Unmarshal(signed, &value, 0); err != nil {
+		t.Errorf("URLSafeTimedSerializer child token failed to verify: %s", err)
+	}
+
+	store := itsdangerous.NewMemoryReplayStore()
+	parentOneTime, err := itsdangerous.NewParentOneTimeSerializer("secret_key", "parent", "", nil, nil, nil, store)
+	if err != nil {
+		t.Fatalf("NewParentOneTimeSerializer returned error: %s", err)
+	}
+	oneTime, err := parentOneTime.WithSalt("salt")
+	if err != nil {
+		t.Fatalf("OneTimeSerializer WithSalt returned error: %s", err)
+	}
+	if oneTime.Store != store {
+		t.Errorf("child Store was not kept")
+	}
+	signed, err = oneTime.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	if err := itsdangerous.NewOneTimeSerializer("secret_key", "salt", store).Unmarshal(signed, &value, 0); err != nil {
+		t.Errorf("OneTimeSerializer child token failed to verify: %s", err)
+	}
+	if err := oneTime.Unmarshal(signed, &value, 0); err == nil {
+		t.Errorf("OneTimeSerializer child token was accepted twice")
+	}
+}
+
+func TestWithSaltErrors(t *testing.T) {
+	options, err := itsdangerous.NewSignerWithOptions("secret_key", "salt", "", "hmac", nil, nil)
+	if err != nil {
+		t.Fatalf("NewSignerWithOptions returned error: %s", err)
+	}
+	for name, s := range map[string]*itsdangerous.Signer{
+		"NewSigner":            itsdangerous.NewSigner("secret_key", "salt"),
+		"NewSignerWithOptions": options,
+	} {
+		if _, err := s.WithSalt("tenant"); err == nil {
+			t.Errorf("%s WithSalt returned no error", name)
+		}
+	}
+	if _, err := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt").WithSalt("tenant"); err == nil {
+		t.Errorf("NewURLSafeTimedSerializer WithSalt returned no error")
+	}
+	if _, err := itsdangerous.NewOneTimeSerializer("secret_key", "salt", nil).WithSalt("tenant"); err == nil {
+		t.Errorf("NewOneTimeSerializer WithSalt returned no error")
+	}
+}
+
+func TestChildCacheEviction(t *testing.T) {
+	cache := itsdangerous.NewChildCache(2)
+	parent := newParentSigner(t, "secret_key", "salt")
+	parent.ChildCache = cache
+	other := newParentSigner(t, "other_secret", "salt")
+	other.ChildCache = cache
+
+	for _, s := range []*itsdangerous.Signer{parent, other} {
+		for i := 0; i < 3; i++ {
+			child, err := s.WithSalt(fmt.Sprint("tenant", i))
+			if err != nil {
+				t.Fatalf("WithSalt returned error: %s", err)
+			}
+			if cache.Len() > 2 {
+				t.Errorf("ChildCache Len() got %d; want at most 2", cache.Len())
+			}
+			// Signers sharing a cache must not share children.
+			if child.Fingerprint() == parent.Fingerprint() || child.Fingerprint() == other.Fingerprint() {
+				t.Errorf("child has the fingerprint of its parent")
+			}
+		}
+	}
+
+	a, _ := parent.WithSalt("tenant")
+	b, _ := other.WithSalt("tenant")
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Errorf("children of different parents with a shared cache have the same fingerprint")
+	}
+}
@@ -0,0 +1,342 @@
+// Command itsdangerous manages keyring files for use with
+// itsdangerous.LoadKeyringFile, and prints key fingerprints.
+//
+// Usage:
+//
+//	itsdangerous keys generate [-file keyring.json] [-id ID] [-derivation NAME] [-digest NAME] [-force]
+//	itsdangerous keys rotate [-file keyring.json] [-id ID] [-derivation NAME] [-digest NAME] [-stage]
+//	itsdangerous keys activate [-file keyring.json] ID
+//	itsdangerous keys retire [-file keyring.json] ID
+//	itsdangerous keys list [-file keyring.json]
+//	itsdangerous fingerprint [-salt SALT] [-derivation NAME] [-digest NAME] [-encoding raw|base64|hex] -secret-env NAME | -secret-file PATH
+//	itsdangerous fingerprint [-salt SALT] -keyring keyring.json
+//
+// generate creates a new keyring file with a single active key. rotate adds a
+// new active key and makes the previously active keys verify-only, so tokens
+// they signed are still accepted. retire stops a key being accepted at all.
+// list shows the keys without their secrets.
+//
+// Services which reload the keyring file, eg with Keyring.Poll, don't all
+// reload it at once, so those which have not yet seen a new key reject tokens
+// signed with it. To avoid this, rotate in two steps: rotate with -stage adds
+// the new key as verify-only, so it is accepted but not yet used to sign, and
+// once every service has reloaded the file, activate makes it the active key
+// and the previously active keys verify-only.
+//
+// fingerprint prints the fingerprint of the signer made with the given secret
+// and options, as returned by Signer.Fingerprint. Services with matching
+// fingerprints share the same effective key. For a keyring file the first row,
+// "(keyring)", is the fingerprint of a signer using the keyring, as from
+// LoadKeyringFile and NewSignerWithKeyring, which covers every key; it is
+// followed by the fingerprint of a signer using each key on its own.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+const usage = `usage:
+  itsdangerous keys generate [-file keyring.json] [-id ID] [-derivation NAME] [-digest NAME] [-force]
+  itsdangerous keys rotate [-file keyring.json] [-id ID] [-derivation NAME] [-digest NAME] [-stage]
+  itsdangerous keys activate [-file keyring.json] ID
+  itsdangerous keys retire [-file keyring.json] ID
+  itsdangerous keys list [-file keyring.json]
+  itsdangerous fingerprint [-salt SALT] [-derivation NAME] [-digest NAME] [-encoding raw|base64|hex] -secret-env NAME | -secret-file PATH
+  itsdangerous fingerprint [-salt SALT] -keyring keyring.json
+`
+
+// errUsage is returned when the command line is invalid.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, "itsdangerous:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run runs the command with the given arguments, excluding the program name.
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "keys":
+			return runKeys(args[1:], stdout, stderr)
+		case "fingerprint":
+			return runFingerprint(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprint(stderr, usage)
+	return errUsage
+}
+
+func runKeys(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+
+	cmd, args := args[0], args[1:]
+	flags := flag.NewFlagSet("keys "+cmd, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("file", "keyring.json", "keyring `file`")
+	var id, derivation, digest *string
+	var force, stage *bool
+	switch cmd {
+	case "generate", "rotate":
+		id = flags.String("id", "", "`ID` of the new key (default based on the current time)")
+		derivation = flags.String("derivation", "", "key derivation `name` for the new key")
+		digest = flags.String("digest", "", "digest `name` for the new key")
+		if cmd == "generate" {
+			force = flags.Bool("force", false, "overwrite an existing keyring file")
+		} else {
+			stage = flags.Bool("stage", false, "add the new key as verify-only, to be made active later with activate")
+		}
+	case "activate", "retire", "list":
+	default:
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	nargs := 0
+	if cmd == "activate" || cmd == "retire" {
+		nargs = 1
+	}
+	if flags.NArg() != nargs {
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+
+	switch cmd {
+	case "generate":
+		return generate(*file, *id, *derivation, *digest, *force)
+	case "rotate":
+		return rotate(*file, *id, *derivation, *digest, *stage)
+	case "activate":
+		return activate(*file, flags.Arg(0))
+	case "retire":
+		return retire(*file, flags.Arg(0))
+	default:
+		return list(*file, stdout)
+	}
+}
+
+func runFingerprint(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("fingerprint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	salt := flags.String("salt", "", "`salt` of the signer")
+	derivation := flags.String("derivation", "", "key derivation `name`")
+	digest := flags.String("digest", "", "digest `name`")
+	encoding := flags.String("encoding", "raw", "secret `encoding`, one of raw, base64 or hex")
+	secretEnv := flags.String("secret-env", "", "environment variable `name` containing the secret")
+	secretFile := flags.String("secret-file", "", "`file` containing the secret")
+	keyring := flags.String("keyring", "", "keyring `file` to print the fingerprints of, and of each key in")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	sources := 0
+	for _, s := range []string{*secretEnv, *secretFile, *keyring} {
+		if s != "" {
+			sources++
+		}
+	}
+	if flags.NArg() != 0 || sources != 1 {
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+
+	if *keyring != "" {
+		return keyringFingerprints(*keyring, *salt, stdout)
+	}
+
+	secretEncoding := itsdangerous.SecretEncoding(*encoding)
+	if secretEncoding == "raw" {
+		secretEncoding = itsdangerous.RawSecret
+	}
+	source := itsdangerous.FromEnv(*secretEnv, secretEncoding)
+	if *secretFile != "" {
+		source = itsdangerous.FromFile(*secretFile, secretEncoding)
+	}
+	var digestMethod func() hash.Hash
+	if *digest != "" {
+		var err error
+		if digestMethod, err = itsdangerous.DigestByName(*digest); err != nil {
+			return err
+		}
+	}
+	s, err := itsdangerous.NewSignerFromSource(source, *salt, "", *derivation, digestMethod, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, s.Fingerprint())
+	return nil
+}
+
+// keyringFingerprints prints the fingerprint of a signer using the keyring
+// file, then of a signer using each key in it on its own.
+func keyringFingerprints(path, salt string, stdout io.Writer) error {
+	keyring, err := itsdangerous.LoadKeyringFile(path, salt)
+	if err != nil {
+		return err
+	}
+	s, err := itsdangerous.NewSignerWithKeyring(keyring, "")
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFINGERPRINT")
+	// Key IDs can't contain parentheses, so this can't be mistaken for one.
+	fmt.Fprintf(w, "(keyring)\t%s\n", s.Fingerprint())
+	for _, key := range keyring.Keys() {
+		s, err := itsdangerous.NewSignerWithKeyDerivation(key.Secret, salt, "", key.Derivation, key.Digest, nil)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key.ID, err)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", key.ID, s.Fingerprint())
+	}
+	return w.Flush()
+}
+
+func generate(path, id, derivation, digest string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists; use -force to overwrite it", path)
+	}
+	return write(path, &itsdangerous.KeyringFile{
+		Version: itsdangerous.KeyringFileVersion,
+		Keys:    []itsdangerous.KeyringFileKey{newKey(id, derivation, digest)},
+	})
+}
+
+func rotate(path, id, derivation, digest string, stage bool) error {
+	f, err := itsdangerous.ReadKeyringFile(path)
+	if err != nil {
+		return err
+	}
+	// Default to the settings of the newest key.
+	if n := len(f.Keys); n > 0 {
+		if derivation == "" {
+			derivation = f.Keys[n-1].Derivation
+		}
+		if digest == "" {
+			digest = f.Keys[n-1].Digest
+		}
+	}
+	key := newKey(id, derivation, digest)
+	if stage {
+		key.Status = itsdangerous.KeyVerifyOnly
+	} else {
+		deactivate(f)
+	}
+	f.Keys = append(f.Keys, key)
+	return write(path, f)
+}
+
+// activate makes the key with the given ID active, usually after it was
+// added by rotate with -stage, and the previously active keys verify-only.
+func activate(path, id string) error {
+	f, err := itsdangerous.ReadKeyringFile(path)
+	if err != nil {
+		return err
+	}
+	for i, key := range f.Keys {
+		if key.ID != id {
+			continue
+		}
+		if key.Status == itsdangerous.KeyRetired {
+			return fmt.Errorf("key %q is retired", id)
+		}
+		deactivate(f)
+		f.Keys[i].Status = itsdangerous.KeyActive
+		return write(path, f)
+	}
+	return fmt.Errorf("no key %q in %s", id, path)
+}
+
+// deactivate makes the active keys verify-only, so tokens they signed are
+// still accepted.
+func deactivate(f *itsdangerous.KeyringFile) {
+	for i, key := range f.Keys {
+		if key.Status == itsdangerous.KeyActive || key.Status == "" {
+			f.Keys[i].Status = itsdangerous.KeyVerifyOnly
+		}
+	}
+}
+
+func retire(path, id string) error {
+	f, err := itsdangerous.ReadKeyringFile(path)
+	if err != nil {
+		return err
+	}
+	for i, key := range f.Keys {
+		if key.ID == id {
+			f.Keys[i].Status = itsdangerous.KeyRetired
+			return write(path, f)
+		}
+	}
+	return fmt.Errorf("no key %q in %s", id, path)
+}
+
+func list(path string, stdout io.Writer) error {
+	f, err := itsdangerous.ReadKeyringFile(path)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tCREATED\tDERIVATION\tDIGEST")
+	for _, key := range f.Keys {
+		status := key.Status
+		if status == "" {
+			status = itsdangerous.KeyActive
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key.ID, status, key.Created.Format(time.RFC3339),
+			orDefault(key.Derivation), orDefault(key.Digest))
+	}
+	return w.Flush()
+}
+
+func orDefault(s string) string {
+	if s == "" {
+		return "default"
+	}
+	return s
+}
+
+// newKey creates a new active key with a random secret.
+func newKey(id, derivation, digest string) itsdangerous.KeyringFileKey {
+	now := time.Now().UTC().Truncate(time.Second)
+	if id == "" {
+		id = now.Format("20060102-150405")
+	}
+	return itsdangerous.KeyringFileKey{
+		ID:         id,
+		Created:    now,
+		Status:     itsdangerous.KeyActive,
+		Secret:     itsdangerous.GenerateSecret(),
+		Derivation: derivation,
+		Digest:     digest,
+	}
+}
+
+// write checks the keyring file is valid and writes it to path.
+func write(path string, f *itsdangerous.KeyringFile) error {
+	keys, err := f.KeyringKeys()
+	if err != nil {
+		return err
+	}
+	if _, err := itsdangerous.NewKeyring(keys, ""); err != nil {
+		return err
+	}
+	return f.Write(path)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	for _, args := range [][]string{
+		{"keys", "generate", "-file", path, "-id", "first", "-digest", "sha256"},
+		{"keys", "rotate", "-file", path, "-id", "second"},
+		{"keys", "rotate", "-file", path, "-id", "third", "-derivation", "hmac"},
+		{"keys", "retire", "-file", path, "first"},
+	} {
+		if err := run(args, io.Discard, io.Discard); err != nil {
+			t.Fatalf("run(%v) returned error: %s", args, err)
+		}
+	}
+
+	f, err := itsdangerous.ReadKeyringFile(path)
+	if err != nil {
+		t.Fatalf("ReadKeyringFile returned error: %s", err)
+	}
+	expected := []itsdangerous.KeyringFileKey{
+		{ID: "first", Status: itsdangerous.KeyRetired, Digest: "sha256"},
+		{ID: "second", Status: itsdangerous.KeyVerifyOnly, Digest: "sha256"},
+		{ID: "third", Status: itsdangerous.KeyActive, Derivation: "hmac", Digest: "sha256"},
+	}
+	if len(f.Keys) != len(expected) {
+		t.Fatalf("keyring file has %d keys; want %d", len(f.Keys), len(expected))
+	}
+	secrets := map[string]bool{}
+	for i, key := range f.Keys {
+		if key.ID != expected[i].ID || key.Status != expected[i].Status ||
+			key.Derivation != expected[i].Derivation || key.Digest != expected[i].Digest {
+			t.Errorf("key %d got %+v; want %+v", i, key, expected[i])
+		}
+		if len(key.Secret) < 32 || secrets[key.Secret] {
+			t.Errorf("key %s has weak or repeated secret", key.ID)
+		}
+		secrets[key.Secret] = true
+	}
+
+	if _, err := itsdangerous.LoadKeyringFile(path, "salt"); err != nil {
+		t.Errorf("LoadKeyringFile returned error: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"keys", "list", "-file", path}, &out, io.Discard); err != nil {
+		t.Fatalf("keys list returned error: %s", err)
+	}
+	for _, s := range []string{"first", "retired", "second", "verify-only", "third", "active", "hmac"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("keys list output missing %s:\n%s", s, out.String())
+		}
+	}
+	for _, key := range f.Keys {
+		if strings.Contains(out.String(), key.Secret) {
+			t.Errorf("keys list output contains secret of key %s", key.ID)
+		}
+	}
+}
+
+func TestKeysStagedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	statuses := func() map[string]itsdangerous.KeyStatus {
+		t.Helper()
+		f, err := itsdangerous.ReadKeyringFile(path)
+		if err != nil {
+			t.Fatalf("ReadKeyringFile returned error: %s", err)
+		}
+		m := map[string]itsdangerous.KeyStatus{}
+		for _, key := range f.Keys {
+			m[key.ID] = key.Status
+		}
+		return m
+	}
+
+	for _, args := range [][]string{
+		{"keys", "generate", "-file", path, "-id", "first"},
+		{"keys", "rotate", "-file", path, "-id", "second", "-stage"},
+	} {
+		if err := run(args, io.Discard, io.Discard); err != nil {
+			t.Fatalf("run(%v) returned error: %s", args, err)
+		}
+	}
+	if actual := statuses(); actual["first"] != itsdangerous.KeyActive || actual["second"] != itsdangerous.KeyVerifyOnly {
+		t.Errorf("statuses after staged rotate got %v; want first active and second verify-only", actual)
+	}
+
+	if err := run([]string{"keys", "activate", "-file", path, "second"}, io.Discard, io.Discard); err != nil {
+		t.Fatalf("keys activate returned error: %s", err)
+	}
+	if actual := statuses(); actual["first"] != itsdangerous.KeyVerifyOnly || actual["second"] != itsdangerous.KeyActive {
+		t.Errorf("statuses after activate got %v; want first verify-only and second active", actual)
+	}
+}
+
+func TestKeysErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	if err := run([]string{"keys", "generate", "-file", path}, io.Discard, io.Discard); err != nil {
+		t.Fatalf("keys generate returned error: %s", err)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"keys"},
+		{"keys", "unknown"},
+		{"keys", "generate", "-file", path},
+		{"keys", "generate", "-file", path + ".new", "-digest", "md5"},
+		{"keys", "retire", "-file", path},
+		{"keys", "retire", "-file", path, "missing"},
+		{"keys", "activate", "-file", path},
+		{"keys", "activate", "-file", path, "missing"},
+		{"keys", "generate", "-stage", "-file", path + ".new"},
+		{"keys", "list", "-file", path, "extra"},
+	} {
+		if err := run(args, io.Discard, io.Discard); err == nil {
+			t.Errorf("run(%v) expected error", args)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Setenv("ITSDANGEROUS_SECRET", "c2VjcmV0X2tleQ")
+	expected := itsdangerous.NewSigner("secret_key", "salt").Fingerprint()
+
+	var out bytes.Buffer
+	args := []string{"fingerprint", "-salt", "salt", "-secret-env", "ITSDANGEROUS_SECRET", "-encoding", "base64"}
+	if err := run(args, &out, io.Discard); err != nil {
+		t.Fatalf("run(%v) returned error: %s", args, err)
+	}
+	if actual := strings.TrimSpace(out.String()); actual != expected {
+		t.Errorf("run(%v) got %s; want %s", args, actual, expected)
+	}
+
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	f := &itsdangerous.KeyringFile{
+		Version: itsdangerous.KeyringFileVersion,
+		Keys:    []itsdangerous.KeyringFileKey{{ID: "current", Status: itsdangerous.KeyActive, Secret: "secret_key"}},
+	}
+	if err := f.Write(path); err != nil {
+		t.Fatalf("Write returned error: %s", err)
+	}
+	out.Reset()
+	args = []string{"fingerprint", "-salt", "salt", "-keyring", path}
+	if err := run(args, &out, io.Discard); err != nil {
+		t.Fatalf("run(%v) returned error: %s", args, err)
+	}
+	if !strings.Contains(out.String(), "current") || !strings.Contains(out.String(), expected) {
+		t.Errorf("run(%v) got %s; want fingerprint %s for key current", args, out.String(), expected)
+	}
+	// The keyring's own fingerprint is what a signer using it reports.
+	keyring, err := itsdangerous.LoadKeyringFile(path, "salt")
+	if err != nil {
+		t.Fatalf("LoadKeyringFile returned error: %s", err)
+	}
+	s, err := itsdangerous.NewSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithKeyring returned error: %s", err)
+	}
+	if want := "(keyring)  " + s.Fingerprint(); !strings.Contains(out.String(), want) {
+		t.Errorf("run(%v) got %s; want it to contain %s", args, out.String(), want)
+	}
+
+	for _, args := range [][]string{
+		{"fingerprint"},
+		{"fingerprint", "-secret-env", "ITSDANGEROUS_SECRET", "-keyring", path},
+		{"fingerprint", "-secret-env", "ITSDANGEROUS_UNSET"},
+		{"fingerprint", "-secret-env", "ITSDANGEROUS_SECRET", "-digest", "md5"},
+	} {
+		if err := run(args, io.Discard, io.Discard); err == nil {
+			t.Errorf("run(%v) expected error", args)
+		}
+	}
+}
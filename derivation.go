@@ -0,0 +1,131 @@
+package itsdangerous
+
+import (
+	"crypto/hmac"
+	"errors"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// KeyDerivation provides an interface to derive the signing key from a secret
+// and salt. Keep in mind that the key derivation in itsdangerous is not
+// intended to be used as a security method to make a complex key out of a
+// short password. Instead you should use large random secret keys.
+type KeyDerivation interface {
+	DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error)
+}
+
+// ConcatDerivation derives the key by hashing the salt and secret
+// concatenated. This is the "concat" method in Python itsdangerous.
+type ConcatDerivation struct{}
+
+// DeriveKey returns the derived key for the given secret and salt.
+func (ConcatDerivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	h := digest()
+	h.Write([]byte(salt + secret))
+	return h.Sum(nil), nil
+}
+
+// DjangoConcatDerivation derives the key by hashing the salt, the string
+// "signer" and the secret concatenated. This is the "django-concat" method in
+// Python itsdangerous, and the default.
+type DjangoConcatDerivation struct{}
+
+// DeriveKey returns the derived key for the given secret and salt.
+func (DjangoConcatDerivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	h := digest()
+	h.Write([]byte(salt + "signer" + secret))
+	return h.Sum(nil), nil
+}
+
+// HMACDerivation derives the key as the HMAC of the salt keyed with the
+// secret. This is the "hmac" method in Python itsdangerous.
+type HMACDerivation struct{}
+
+// DeriveKey returns the derived key for the given secret and salt.
+func (HMACDerivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	h := hmac.New(digest, []byte(secret))
+	h.Write([]byte(salt))
+	return h.Sum(nil), nil
+}
+
+// NoDerivation uses the secret as the key directly, ignoring the salt. This
+// is the "none" method in Python itsdangerous.
+type NoDerivation struct{}
+
+// DeriveKey returns the secret as the key.
+func (NoDerivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	return []byte(secret), nil
+}
+
+// HKDFDerivation derives the key with HKDF (RFC 5869), using the salt as the
+// HKDF salt. This is the "hkdf" method, which is not available in Python
+// itsdangerous.
+type HKDFDerivation struct {
+	// Info is the optional HKDF context information.
+	Info string
+}
+
+// DeriveKey returns the derived key for the given secret and salt.
+func (d HKDFDerivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	key := make([]byte, digest().Size())
+	if _, err := io.ReadFull(hkdf.New(digest, []byte(secret), []byte(salt), []byte(d.Info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DefaultPBKDF2Iterations is the iteration count used by PBKDF2Derivation
+// when none is given.
+const DefaultPBKDF2Iterations = 600000
+
+// PBKDF2Derivation derives the key with PBKDF2 (RFC 8018), for the rare case
+// that the secret is a human-chosen passphrase. This is the "pbkdf2" method,
+// which is not available in Python itsdangerous. PBKDF2 is deliberately slow,
+// but this only affects constructing a Signer as the derived key is kept.
+type PBKDF2Derivation struct {
+	// Iterations is the PBKDF2 iteration count. Zero means
+	// DefaultPBKDF2Iterations.
+	Iterations int
+}
+
+// DeriveKey returns the derived key for the given secret and salt.
+func (d PBKDF2Derivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	iterations := d.Iterations
+	if iterations == 0 {
+		iterations = DefaultPBKDF2Iterations
+	}
+	return pbkdf2.Key([]byte(secret), []byte(salt), iterations, digest().Size(), digest), nil
+}
+
+// KeyDerivationByName returns the KeyDerivation for one of the method names
+// accepted by NewSignerWithOptions.
+func KeyDerivationByName(name string) (KeyDerivation, error) {
+	switch name {
+	case "concat":
+		return ConcatDerivation{}, nil
+	case "django-concat":
+		return DjangoConcatDerivation{}, nil
+	case "hmac":
+		return HMACDerivation{}, nil
+	case "hkdf":
+		return HKDFDerivation{}, nil
+	case "pbkdf2":
+		return PBKDF2Derivation{}, nil
+	case "none":
+		return NoDerivation{}, nil
+	}
+	return nil, errors.New("unknown key derivation method " + name)
+}
+
+// deriveKey generates a key derivation using the named method.
+func deriveKey(secretKey, salt, keyDerivation string, digestMethod func() hash.Hash) ([]byte, error) {
+	d, err := KeyDerivationByName(keyDerivation)
+	if err != nil {
+		return nil, err
+	}
+	return d.DeriveKey(secretKey, salt, digestMethod)
+}
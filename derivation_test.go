@@ -0,0 +1,100 @@
+package itsdangerous_test
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestKeyDerivation(t *testing.T) {
+	tests := []struct {
+		name       string
+		derivation itsdangerous.KeyDerivation
+		secret     string
+		salt       string
+		digest     func() hash.Hash
+		expected   string
+	}{
+		// RFC 5869 test case 1, truncated to the digest size
+		{name: "hkdf", derivation: itsdangerous.HKDFDerivation{Info: "\xf0\xf1\xf2\xf3\xf4\xf5\xf6\xf7\xf8\xf9"},
+			secret: strings.Repeat("\x0b", 22), salt: "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c",
+			digest: sha256.New, expected: "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf"},
+		// RFC 6070 test vectors
+		{name: "pbkdf2 1", derivation: itsdangerous.PBKDF2Derivation{Iterations: 1},
+			secret: "password", salt: "salt", digest: sha1.New, expected: "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{name: "pbkdf2 2", derivation: itsdangerous.PBKDF2Derivation{Iterations: 2},
+			secret: "password", salt: "salt", digest: sha1.New, expected: "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{name: "pbkdf2 4096", derivation: itsdangerous.PBKDF2Derivation{Iterations: 4096},
+			secret: "password", salt: "salt", digest: sha1.New, expected: "4b007901b765489abead49d926f721d065a429c1"},
+		{name: "none", derivation: itsdangerous.NoDerivation{},
+			secret: "secret_key", salt: "salt", digest: sha1.New, expected: hex.EncodeToString([]byte("secret_key"))},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			key, err := test.derivation.DeriveKey(test.secret, test.salt, test.digest)
+			if err != nil {
+				t.Fatalf("DeriveKey returned error: %s", err)
+			}
+			if actual := hex.EncodeToString(key); actual != test.expected {
+				t.Errorf("DeriveKey got %s; want %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+// reversedDerivation is a custom KeyDerivation which ignores the salt and
+// uses the secret reversed as the key.
+type reversedDerivation struct{}
+
+func (reversedDerivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	key := []byte(secret)
+	for i, j := 0, len(key)-1; i < j; i, j = i+1, j-1 {
+		key[i], key[j] = key[j], key[i]
+	}
+	return key, nil
+}
+
+func TestNewSignerWithKeyDerivation(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		derivation itsdangerous.KeyDerivation
+		expected   string
+	}{
+		// Matches NewSigner
+		{name: "default", expected: "my string.xv0r21ogoygusbkJA01c4OxsAio"},
+		{name: "django-concat", derivation: itsdangerous.DjangoConcatDerivation{},
+			expected: "my string.xv0r21ogoygusbkJA01c4OxsAio"},
+		{name: "concat", derivation: itsdangerous.ConcatDerivation{},
+			expected: "my string.9kdknIv7LWbox-Hf2QREZJ13rTE"},
+		{name: "hmac", derivation: itsdangerous.HMACDerivation{},
+			expected: "my string.Q8ZXuvMJCFLTugCvWcfbJUM1EMk"},
+		// Reversed back to "secret_key" so equivalent to "none"
+		{name: "custom", secret: "yek_terces", derivation: reversedDerivation{},
+			expected: "my string.oolbhe8yXballsNy-1bwGYuEYec"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			secret := test.secret
+			if secret == "" {
+				secret = "secret_key"
+			}
+			sig, err := itsdangerous.NewTimestampSignerWithKeyDerivation(secret, "salt", "", test.derivation, nil, nil)
+			if err != nil {
+				t.Fatalf("NewTimestampSignerWithKeyDerivation returned error: %s", err)
+			}
+
+			actual := sig.Signer.Sign("my string")
+			if actual != test.expected {
+				t.Errorf("Sign(my string) got %s; want %s", actual, test.expected)
+			}
+		})
+	}
+}
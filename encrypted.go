@@ -0,0 +1,121 @@
+package itsdangerous
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// EncryptedSerializer works like URLSafeTimedSerializer but encrypts the
+// payload with AES-256-GCM, so tokens are both tamper-proof and unreadable
+// without the secret.
+//
+// Multiple secrets can be given to support key rotation. As in Python
+// itsdangerous they are ordered oldest to newest: the last secret is used to
+// encrypt new tokens and all of them are tried when decrypting.
+type EncryptedSerializer struct {
+	aeads []cipher.AEAD
+	// fingerprint identifies the newest key, for formatting.
+	fingerprint string
+}
+
+// NewEncryptedSerializer creates a new EncryptedSerializer with the given
+// secrets and salt. Each secret is turned into an AES key using the "hmac" key
+// derivation with SHA-256.
+func NewEncryptedSerializer(secrets []string, salt string) (*EncryptedSerializer, error) {
+	if len(secrets) == 0 {
+		return nil, errors.New("at least one secret is required")
+	}
+	if salt == "" {
+		salt = "itsdangerous.EncryptedSerializer"
+	}
+	s := &EncryptedSerializer{}
+	// Store newest first, which is the order keys are tried in.
+	for i := len(secrets) - 1; i >= 0; i-- {
+		key, err := deriveKey(secrets[i], salt, "hmac", sha256.New)
+		if err != nil {
+			return nil, err
+		}
+		if i == len(secrets)-1 {
+			s.fingerprint = keyFingerprint("AES-256-GCM", key)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		s.aeads = append(s.aeads, aead)
+	}
+	return s, nil
+}
+
+// Marshal compresses and encrypts the given value along with the current
+// timestamp.
+func (s *EncryptedSerializer) Marshal(value interface{}) (string, error) {
+	payload, compressed, err := compressPayload(value)
+	if err != nil {
+		return "", err
+	}
+
+	// The plaintext is the 8 byte timestamp, a compression flag byte, and
+	// the payload.
+	plaintext := make([]byte, 9, 9+len(payload))
+	binary.BigEndian.PutUint64(plaintext, uint64(getTimestamp()))
+	if compressed {
+		plaintext[8] = 1
+	}
+	plaintext = append(plaintext, payload...)
+
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64Encode(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Unmarshal decrypts the given token into value, checking it is no older than
+// maxAge. A maxAge of zero disables the age check.
+func (s *EncryptedSerializer) Unmarshal(token string, value interface{}, maxAge time.Duration) error {
+	decoded, err := base64Decode(token)
+	if err != nil {
+		return InvalidSignatureError{err}
+	}
+
+	plaintext, err := s.open(decoded)
+	if err != nil {
+		return err
+	}
+	if len(plaintext) < 9 {
+		return InvalidSignatureError{errors.New("payload too short")}
+	}
+
+	timestamp := int64(binary.BigEndian.Uint64(plaintext))
+	if age := time.Duration(getTimestamp()-timestamp) * time.Second; maxAge > 0 && age > maxAge {
+		return signatureExpired(age, maxAge)
+	}
+
+	return decompressPayload(plaintext[9:], plaintext[8] == 1, value)
+}
+
+// open decrypts the given ciphertext with the first key that accepts it.
+func (s *EncryptedSerializer) open(ciphertext []byte) ([]byte, error) {
+	for _, aead := range s.aeads {
+		if len(ciphertext) < aead.NonceSize() {
+			break
+		}
+		nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, sealed, nil); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, InvalidSignatureError{errors.New("token could not be decrypted")}
+}
@@ -0,0 +1,132 @@
+package itsdangerous_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestEncryptedSerializer(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+	}{
+		{name: "string", payload: "my string"},
+		{name: "map", payload: map[string]interface{}{"email": "user@example.com"}},
+		{name: "long string", payload: strings.Repeat("a", 100)},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			sig, err := itsdangerous.NewEncryptedSerializer([]string{"secret_key"}, "salt")
+			if err != nil {
+				t.Fatalf("NewEncryptedSerializer returned error: %s", err)
+			}
+
+			token, err := sig.Marshal(test.payload)
+			if err != nil {
+				t.Fatalf("Marshal returned error: %s", err)
+			}
+			if strings.Contains(token, "user") || strings.Contains(token, "ZW1haWwi") {
+				t.Errorf("Marshal() got %s; payload is not encrypted", token)
+			}
+
+			var decoded interface{}
+			err = sig.Unmarshal(token, &decoded, time.Minute)
+			if err != nil {
+				t.Fatalf("Marshal result could not be unmarshalled: %s", err)
+			}
+			if !reflect.DeepEqual(decoded, test.payload) {
+				t.Errorf("Marshal round-trip changed payload. Got %#v, want %#v", decoded, test.payload)
+			}
+		})
+	}
+}
+
+func TestEncryptedSerializerUnmarshal(t *testing.T) {
+	itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC) }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	old, err := itsdangerous.NewEncryptedSerializer([]string{"old_key"}, "salt")
+	if err != nil {
+		t.Fatalf("NewEncryptedSerializer returned error: %s", err)
+	}
+	oldToken, err := old.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	rotated, err := itsdangerous.NewEncryptedSerializer([]string{"old_key", "new_key"}, "salt")
+	if err != nil {
+		t.Fatalf("NewEncryptedSerializer returned error: %s", err)
+	}
+	newToken, err := rotated.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(newToken)
+	raw[len(raw)-1] ^= 1
+	altered := base64.RawURLEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name          string
+		token         string
+		secrets       []string
+		salt          string
+		now           time.Time
+		expectError   bool
+		expectExpired bool
+	}{
+		{name: "old key", token: oldToken, secrets: []string{"old_key", "new_key"}, salt: "salt"},
+		{name: "new key", token: newToken, secrets: []string{"old_key", "new_key"}, salt: "salt"},
+		{name: "retired key", token: oldToken, secrets: []string{"new_key"}, salt: "salt", expectError: true},
+		{name: "wrong salt", token: newToken, secrets: []string{"new_key"}, salt: "other", expectError: true},
+		{name: "altered", token: altered, secrets: []string{"new_key"}, salt: "salt", expectError: true},
+		{name: "not base64", token: "a.b", secrets: []string{"new_key"}, salt: "salt", expectError: true},
+		{name: "too short", token: "YWJj", secrets: []string{"new_key"}, salt: "salt", expectError: true},
+		{name: "within maxAge", token: newToken, secrets: []string{"new_key"}, salt: "salt",
+			now: time.Date(2024, 9, 27, 14, 4, 59, 0, time.UTC)},
+		{name: "expired", token: newToken, secrets: []string{"new_key"}, salt: "salt",
+			now: time.Date(2024, 9, 27, 14, 5, 1, 0, time.UTC), expectError: true, expectExpired: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if !test.now.IsZero() {
+				itsdangerous.NowFunc = func() time.Time { return test.now }
+				defer func() { itsdangerous.NowFunc = time.Now }()
+			}
+
+			sig, err := itsdangerous.NewEncryptedSerializer(test.secrets, test.salt)
+			if err != nil {
+				t.Fatalf("NewEncryptedSerializer returned error: %s", err)
+			}
+
+			var actual interface{}
+			err = sig.Unmarshal(test.token, &actual, 5*time.Minute)
+			if test.expectError {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("Unmarshal(%s) expected InvalidSignatureError; got %v", test.token, err)
+				}
+				if test.expectExpired != errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+					t.Fatalf("Unmarshal(%s) expired = %t; got %v", test.token, test.expectExpired, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unmarshal(%s) returned error: %s", test.token, err)
+				}
+				if actual != "my string" {
+					t.Errorf("Unmarshal(%s) got %#v; want %#v", test.token, actual, "my string")
+				}
+			}
+		})
+	}
+
+	if _, err := itsdangerous.NewEncryptedSerializer(nil, "salt"); err == nil {
+		t.Errorf("NewEncryptedSerializer(nil) expected error; got no error")
+	}
+}
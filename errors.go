@@ -1,6 +1,9 @@
 package itsdangerous
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type InvalidSignatureError struct {
 	err error
@@ -10,13 +13,95 @@ func (e InvalidSignatureError) Error() string { return e.err.Error() }
 func (e InvalidSignatureError) Unwrap() error { return e.err }
 
 type SignatureExpiredError struct {
-	age, maxAge int64
+	age, maxAge time.Duration
 }
 
 func (e SignatureExpiredError) Error() string {
-	return fmt.Sprintf("signature age %d > %d seconds", e.age, e.maxAge)
+	return fmt.Sprintf("signature age %s > %s", e.age, e.maxAge)
 }
 
-func signatureExpired(age, maxAge int64) error {
+func signatureExpired(age, maxAge time.Duration) error {
 	return InvalidSignatureError{SignatureExpiredError{age: age, maxAge: maxAge}}
 }
+
+type WrongPurposeError struct {
+	purpose, expected string
+}
+
+func (e WrongPurposeError) Error() string {
+	return fmt.Sprintf("token purpose %q does not match %q", e.purpose, e.expected)
+}
+
+func wrongPurpose(purpose, expected string) error {
+	return InvalidSignatureError{WrongPurposeError{purpose: purpose, expected: expected}}
+}
+
+type TokenReusedError struct {
+	id string
+}
+
+func (e TokenReusedError) Error() string {
+	return fmt.Sprintf("token %s has already been used", e.id)
+}
+
+func tokenReused(id string) error {
+	return InvalidSignatureError{TokenReusedError{id: id}}
+}
+
+type RevokedError struct {
+	id string
+}
+
+func (e RevokedError) Error() string {
+	return fmt.Sprintf("signature %s has been revoked", e.id)
+}
+
+func signatureRevoked(id string) error {
+	return InvalidSignatureError{RevokedError{id: id}}
+}
+
+type NotYetValidError struct {
+	notBefore, now time.Time
+}
+
+func (e NotYetValidError) Error() string {
+	return fmt.Sprintf("signature not valid for another %s", e.notBefore.Sub(e.now))
+}
+
+// NotBefore returns the time from which the signature is valid.
+func (e NotYetValidError) NotBefore() time.Time {
+	return e.notBefore
+}
+
+func notYetValid(notBefore, now time.Time) error {
+	return InvalidSignatureError{NotYetValidError{notBefore: notBefore, now: now}}
+}
+
+type UnknownKeyError struct {
+	id string
+}
+
+func (e UnknownKeyError) Error() string {
+	return fmt.Sprintf("signature key %q is not in the keyring", e.id)
+}
+
+// KeyID returns the ID of the unknown key.
+func (e UnknownKeyError) KeyID() string {
+	return e.id
+}
+
+func unknownKey(id string) error {
+	return InvalidSignatureError{UnknownKeyError{id: id}}
+}
+
+type RetiredKeyError struct {
+	id string
+}
+
+func (e RetiredKeyError) Error() string {
+	return fmt.Sprintf("signature key %q has been retired", e.id)
+}
+
+func retiredKey(id string) error {
+	return InvalidSignatureError{RetiredKeyError{id: id}}
+}
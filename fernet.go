@@ -0,0 +1,199 @@
+package itsdangerous
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// See https://github.com/fernet/spec/blob/master/Spec.md for the token format.
+const (
+	fernetVersion = 0x80
+	// Tokens with timestamps further than this in the future are rejected
+	// when a TTL is given.
+	fernetMaxClockSkew = 60
+	// version + timestamp + IV + one cipher block + HMAC
+	fernetMinLength = 1 + 8 + aes.BlockSize + aes.BlockSize + sha256.Size
+)
+
+// Fernet encrypts and decrypts tokens in the Fernet format used by the Python
+// cryptography package. The current time is taken from NowFunc, as with
+// TimestampSigner.
+type Fernet struct {
+	signingKey    []byte
+	encryptionKey []byte
+}
+
+// GenerateFernetKey returns a new random key suitable for NewFernet.
+func GenerateFernetKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(key), nil
+}
+
+// NewFernet creates a new Fernet from a URL-safe base64 encoded 32 byte key.
+func NewFernet(key string) (*Fernet, error) {
+	decoded, err := base64.URLEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != 32 {
+		return nil, errors.New("Fernet key must be 32 url-safe base64-encoded bytes")
+	}
+	return &Fernet{signingKey: decoded[:16], encryptionKey: decoded[16:]}, nil
+}
+
+// Encrypt the given message, returning a Fernet token.
+func (f *Fernet) Encrypt(msg []byte) (string, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	return f.encrypt(msg, iv, getTimestamp())
+}
+
+func (f *Fernet) encrypt(msg, iv []byte, timestamp int64) (string, error) {
+	block, err := aes.NewCipher(f.encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	// PKCS7 padding
+	padding := aes.BlockSize - len(msg)%aes.BlockSize
+	plaintext := append(msg[:len(msg):len(msg)], bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	token := make([]byte, 1+8, fernetMinLength+len(plaintext))
+	token[0] = fernetVersion
+	binary.BigEndian.PutUint64(token[1:], uint64(timestamp))
+	token = append(token, iv...)
+
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+	token = append(token, ciphertext...)
+
+	h := hmac.New(sha256.New, f.signingKey)
+	h.Write(token)
+	token = h.Sum(token)
+
+	return base64.URLEncoding.EncodeToString(token), nil
+}
+
+// Decrypt the given Fernet token. If ttl is greater than zero, tokens older
+// than ttl are rejected with a SignatureExpiredError.
+func (f *Fernet) Decrypt(token string, ttl time.Duration) ([]byte, error) {
+	msg, _, err := f.decrypt(token, ttl)
+	return msg, err
+}
+
+// decrypt works like Decrypt but also returns the token's timestamp.
+func (f *Fernet) decrypt(token string, ttl time.Duration) ([]byte, int64, error) {
+	decoded, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, 0, InvalidSignatureError{err}
+	}
+	if len(decoded) < fernetMinLength || decoded[0] != fernetVersion {
+		return nil, 0, InvalidSignatureError{errors.New("invalid Fernet token")}
+	}
+
+	body, mac := decoded[:len(decoded)-sha256.Size], decoded[len(decoded)-sha256.Size:]
+	h := hmac.New(sha256.New, f.signingKey)
+	h.Write(body)
+	if !hmac.Equal(mac, h.Sum(nil)) {
+		return nil, 0, InvalidSignatureError{errors.New("signature does not match")}
+	}
+
+	timestamp := int64(binary.BigEndian.Uint64(body[1:9]))
+	if ttl > 0 {
+		now := getTimestamp()
+		if age := time.Duration(now-timestamp) * time.Second; age > ttl {
+			return nil, 0, signatureExpired(age, ttl)
+		}
+		if timestamp > now+fernetMaxClockSkew {
+			return nil, 0, InvalidSignatureError{errors.New("Fernet token timestamp is in the future")}
+		}
+	}
+
+	iv, ciphertext := body[9:9+aes.BlockSize], body[9+aes.BlockSize:]
+	if len(ciphertext)%aes.BlockSize != 0 {
+		return nil, 0, InvalidSignatureError{errors.New("ciphertext is not a multiple of the block size")}
+	}
+	block, err := aes.NewCipher(f.encryptionKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize ||
+		!bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, 0, InvalidSignatureError{errors.New("invalid padding")}
+	}
+	return plaintext[:len(plaintext)-padding], timestamp, nil
+}
+
+// MultiFernet supports key rotation across several Fernet keys. As in the
+// Python cryptography package, the first Fernet is used to encrypt and all
+// of them are tried in order when decrypting.
+type MultiFernet struct {
+	fernets []*Fernet
+}
+
+// NewMultiFernet creates a new MultiFernet from the given Fernets, newest
+// first.
+func NewMultiFernet(fernets ...*Fernet) (*MultiFernet, error) {
+	if len(fernets) == 0 {
+		return nil, errors.New("MultiFernet requires at least one Fernet")
+	}
+	return &MultiFernet{fernets: fernets}, nil
+}
+
+// Encrypt the given message with the first Fernet.
+func (m *MultiFernet) Encrypt(msg []byte) (string, error) {
+	return m.fernets[0].Encrypt(msg)
+}
+
+// Decrypt the given token with the first Fernet that accepts it.
+func (m *MultiFernet) Decrypt(token string, ttl time.Duration) ([]byte, error) {
+	msg, _, err := m.decrypt(token, ttl)
+	return msg, err
+}
+
+// Rotate re-encrypts the given token with the first Fernet, preserving its
+// original timestamp.
+func (m *MultiFernet) Rotate(token string) (string, error) {
+	msg, timestamp, err := m.decrypt(token, 0)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	return m.fernets[0].encrypt(msg, iv, timestamp)
+}
+
+func (m *MultiFernet) decrypt(token string, ttl time.Duration) ([]byte, int64, error) {
+	var err error
+	for _, f := range m.fernets {
+		var msg []byte
+		var timestamp int64
+		msg, timestamp, err = f.decrypt(token, ttl)
+		// The MAC is checked before the age, so an expired token was
+		// still signed with this key.
+		if err == nil || errors.As(err, &SignatureExpiredError{}) {
+			return msg, timestamp, err
+		}
+	}
+	return nil, 0, err
+}
@@ -0,0 +1,155 @@
+package itsdangerous
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Vectors from https://github.com/fernet/spec
+const (
+	fernetSpecSecret = "cw_0x689RpI-jtRR7oE8h_eQsKImvJapLeSbXpwF4e4="
+	fernetSpecToken  = "gAAAAAAdwJ6wAAECAwQFBgcICQoLDA0ODy021cpGVWKZ_eEwCGM4BLLF_5CV9dOPmrhuVUPgJobwOz7JcbmrR64jVmpU4IwqDA=="
+)
+
+func TestFernetGenerate(t *testing.T) {
+	f, err := NewFernet(fernetSpecSecret)
+	if err != nil {
+		t.Fatalf("NewFernet returned error: %s", err)
+	}
+
+	now := time.Date(1985, 10, 26, 1, 20, 0, 0, time.FixedZone("", -7*60*60))
+	iv := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	actual, err := f.encrypt([]byte("hello"), iv, now.Unix())
+	if err != nil {
+		t.Fatalf("encrypt returned error: %s", err)
+	}
+	if actual != fernetSpecToken {
+		t.Errorf("encrypt() got %s; want %s", actual, fernetSpecToken)
+	}
+}
+
+func TestFernetDecrypt(t *testing.T) {
+	tamper := func(i int) string {
+		raw, _ := base64.URLEncoding.DecodeString(fernetSpecToken)
+		if i < 0 {
+			i += len(raw)
+		}
+		raw[i] ^= 1
+		return base64.URLEncoding.EncodeToString(raw)
+	}
+	tests := []struct {
+		name          string
+		token         string
+		now           time.Time
+		ttl           time.Duration
+		expectError   bool
+		expectExpired bool
+	}{
+		// The spec's verify vector
+		{name: "valid", token: fernetSpecToken, now: time.Date(1985, 10, 26, 8, 20, 1, 0, time.UTC), ttl: time.Minute},
+		{name: "no ttl", token: fernetSpecToken, now: time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)},
+		{name: "expired", token: fernetSpecToken, now: time.Date(1985, 10, 26, 8, 21, 1, 0, time.UTC), ttl: time.Minute,
+			expectError: true, expectExpired: true},
+		{name: "far future", token: fernetSpecToken, now: time.Date(1985, 10, 26, 8, 18, 59, 0, time.UTC), ttl: time.Minute,
+			expectError: true},
+		{name: "incorrect mac", token: tamper(-1), now: time.Date(1985, 10, 26, 8, 20, 1, 0, time.UTC), ttl: time.Minute,
+			expectError: true},
+		{name: "altered ciphertext", token: tamper(30), now: time.Date(1985, 10, 26, 8, 20, 1, 0, time.UTC), ttl: time.Minute,
+			expectError: true},
+		{name: "wrong version", token: tamper(0), now: time.Date(1985, 10, 26, 8, 20, 1, 0, time.UTC), ttl: time.Minute,
+			expectError: true},
+		{name: "too short", token: fernetSpecToken[:40], now: time.Date(1985, 10, 26, 8, 20, 1, 0, time.UTC),
+			ttl: time.Minute, expectError: true},
+		{name: "invalid base64", token: "%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%%",
+			now: time.Date(1985, 10, 26, 8, 20, 1, 0, time.UTC), ttl: time.Minute, expectError: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			NowFunc = func() time.Time { return test.now }
+			defer func() { NowFunc = time.Now }()
+
+			f, err := NewFernet(fernetSpecSecret)
+			if err != nil {
+				t.Fatalf("NewFernet returned error: %s", err)
+			}
+
+			actual, err := f.Decrypt(test.token, test.ttl)
+			if test.expectError {
+				if !errors.As(err, &InvalidSignatureError{}) {
+					t.Fatalf("Decrypt(%s) expected InvalidSignatureError; got %v", test.token, err)
+				}
+				if test.expectExpired != errors.As(err, &SignatureExpiredError{}) {
+					t.Fatalf("Decrypt(%s) expired = %t; got %v", test.token, test.expectExpired, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Decrypt(%s) returned error: %s", test.token, err)
+				}
+				if string(actual) != "hello" {
+					t.Errorf("Decrypt(%s) got %q; want %q", test.token, actual, "hello")
+				}
+			}
+		})
+	}
+}
+
+func TestMultiFernet(t *testing.T) {
+	NowFunc = func() time.Time { return time.Date(1985, 10, 26, 8, 20, 1, 0, time.UTC) }
+	defer func() { NowFunc = time.Now }()
+
+	newKey, err := GenerateFernetKey()
+	if err != nil {
+		t.Fatalf("GenerateFernetKey returned error: %s", err)
+	}
+	newFernet, err := NewFernet(newKey)
+	if err != nil {
+		t.Fatalf("NewFernet returned error: %s", err)
+	}
+	oldFernet, err := NewFernet(fernetSpecSecret)
+	if err != nil {
+		t.Fatalf("NewFernet returned error: %s", err)
+	}
+	m, err := NewMultiFernet(newFernet, oldFernet)
+	if err != nil {
+		t.Fatalf("NewMultiFernet returned error: %s", err)
+	}
+
+	// Old tokens still decrypt
+	if msg, err := m.Decrypt(fernetSpecToken, time.Minute); err != nil || string(msg) != "hello" {
+		t.Errorf("Decrypt(old token) got %q, %v; want %q", msg, err, "hello")
+	}
+
+	// New tokens use the first key
+	token, err := m.Encrypt([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %s", err)
+	}
+	if _, err := newFernet.Decrypt(token, time.Minute); err != nil {
+		t.Errorf("Encrypt() did not use the first key: %s", err)
+	}
+
+	// Rotation re-encrypts with the first key keeping the timestamp
+	rotated, err := m.Rotate(fernetSpecToken)
+	if err != nil {
+		t.Fatalf("Rotate returned error: %s", err)
+	}
+	if msg, err := newFernet.Decrypt(rotated, time.Minute); err != nil || string(msg) != "hello" {
+		t.Errorf("Decrypt(rotated token) got %q, %v; want %q", msg, err, "hello")
+	}
+	NowFunc = func() time.Time { return time.Date(1985, 10, 26, 8, 21, 1, 0, time.UTC) }
+	if _, err := newFernet.Decrypt(rotated, time.Minute); !errors.As(err, &SignatureExpiredError{}) {
+		t.Errorf("Rotate() did not preserve timestamp; got %v", err)
+	}
+
+	// Expiry is reported rather than treated as an unknown key
+	if _, err := m.Decrypt(fernetSpecToken, time.Minute); !errors.As(err, &SignatureExpiredError{}) {
+		t.Errorf("Decrypt(expired) expected SignatureExpiredError; got %v", err)
+	}
+
+	if _, err := NewMultiFernet(); err == nil {
+		t.Errorf("NewMultiFernet() expected error; got no error")
+	}
+}
@@ -0,0 +1,572 @@
+package itsdangerous
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The signer and serializer types, and the types holding keys, implement
+// fmt.Formatter, fmt.Stringer, fmt.GoStringer and slog.LogValuer so that
+// printing or logging them shows only non-secret metadata, never key
+// material. The methods have value receivers so they are also used when the
+// types are embedded in structs by value.
+
+// description is the non-secret metadata of a signer or serializer.
+type description struct {
+	typ   string
+	attrs []slog.Attr
+}
+
+func (d description) String() string {
+	var b strings.Builder
+	b.WriteString("itsdangerous.")
+	b.WriteString(d.typ)
+	b.WriteByte('{')
+	for i, a := range d.attrs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(a.Key)
+		b.WriteByte(':')
+		if a.Value.Kind() == slog.KindString {
+			b.WriteString(strconv.Quote(a.Value.String()))
+		} else {
+			b.WriteString(a.Value.String())
+		}
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func (d description) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		io.WriteString(f, d.String())
+	case 'q':
+		io.WriteString(f, strconv.Quote(d.String()))
+	default:
+		fmt.Fprintf(f, "%%!%c(itsdangerous.%s)", verb, d.typ)
+	}
+}
+
+func (d description) LogValue() slog.Value {
+	return slog.GroupValue(append([]slog.Attr{slog.String("type", d.typ)}, d.attrs...)...)
+}
+
+// describedBackend is implemented by backends which can describe themselves
+// without revealing secrets.
+type describedBackend interface {
+	algorithmName() string
+	fingerprint() string
+}
+
+func (b *LocalBackend) algorithmName() string {
+	return algorithmName(b.algorithm())
+}
+
+func (b *LocalBackend) fingerprint() string {
+	return signatureFingerprint(b.algorithm(), b.Key)
+}
+
+// describe returns the non-secret metadata of the backend.
+func (b LocalBackend) describe() description {
+	return description{typ: "LocalBackend", attrs: []slog.Attr{
+		slog.String("algorithm", b.algorithmName()),
+		slog.String("fingerprint", b.fingerprint()),
+	}}
+}
+
+func (b LocalBackend) String() string {
+	return b.describe().String()
+}
+
+func (b LocalBackend) GoString() string {
+	return b.describe().String()
+}
+
+func (b LocalBackend) Format(f fmt.State, verb rune) {
+	b.describe().Format(f, verb)
+}
+
+func (b LocalBackend) LogValue() slog.Value {
+	return b.describe().LogValue()
+}
+
+func (k *Keyring) algorithmName() string {
+	ks := k.keys.Load()
+	key, err := ks.signingKey(NowFunc())
+	if err != nil {
+		key = &ks.keys[0]
+	}
+	return algorithmName(key.algorithm)
+}
+
+func (k *Keyring) fingerprint() string {
+	ks := k.keys.Load()
+	parts := make([][]byte, 0, 2*len(ks.keys))
+	for _, key := range ks.keys {
+		parts = append(parts, []byte(key.ID), []byte(signatureFingerprint(key.algorithm, key.derived)))
+	}
+	return keyFingerprint("keyring", parts...)
+}
+
+// signatureFingerprint returns an identifier of the key and algorithm, from
+// the signature of a fixed value. Unlike algorithmName, which is only for
+// display, it doesn't depend on how the algorithm is implemented, eg with
+// boringcrypto, and distinguishes any algorithms which sign differently.
+func signatureFingerprint(algo SigningAlgorithm, key []byte) string {
+	return keyFingerprint("signer", algo.GetSignature(key, "itsdangerous.fingerprint"))
+}
+
+// algorithmName returns a name for the algorithm, such as HMAC-SHA256, for
+// display.
+func algorithmName(algo SigningAlgorithm) string {
+	h, ok := algo.(*HMACAlgorithm)
+	if !ok {
+		return fmt.Sprintf("%T", algo)
+	}
+	digest := h.DigestMethod()
+	// The package name distinguishes digests of the same size, and the
+	// size digests in the same package.
+	typ := fmt.Sprintf("%T", digest)
+	pkg, _, _ := strings.Cut(strings.TrimLeft(typ, "*"), ".")
+	switch size := digest.Size(); {
+	case pkg == "sha1":
+		return "HMAC-SHA1"
+	case pkg == "sha256" && size == 28:
+		return "HMAC-SHA224"
+	case pkg == "sha256":
+		return "HMAC-SHA256"
+	case pkg == "sha512" && size == 48:
+		return "HMAC-SHA384"
+	case pkg == "sha512" && size == 64:
+		return "HMAC-SHA512"
+	default:
+		return fmt.Sprintf("HMAC-%s-%d", strings.ToUpper(pkg), size*8)
+	}
+}
+
+// keyFingerprint returns a short, non-reversible identifier for the given key
+// material.
+func keyFingerprint(name string, parts ...[]byte) string {
+	h := sha256.New()
+	h.Write([]byte("itsdangerous.fingerprint\x00" + name))
+	for _, p := range parts {
+		// Prefix each part with its length so they can't run together.
+		binary.Write(h, binary.BigEndian, uint32(len(p)))
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// Fingerprint returns a stable, non-reversible identifier of the signer's
+// derived key and signing algorithm, computed from the signature of a fixed
+// value. Signers which produce the same signatures have the same
+// fingerprint, and signers with different keys or algorithms almost
+// certainly don't, so it can be used to check that services share a key
+// without comparing secrets. For a Keyring it covers every key and its ID.
+// It is empty if the signing backend is not a LocalBackend or Keyring.
+func (s *Signer) Fingerprint() string {
+	if b, ok := s.backend.(describedBackend); ok {
+		return b.fingerprint()
+	}
+	return ""
+}
+
+// describe returns the non-secret metadata of the signer.
+func (s Signer) describe(typ string) description {
+	var attrs []slog.Attr
+	if b, ok := s.backend.(describedBackend); ok {
+		attrs = append(attrs,
+			slog.String("algorithm", b.algorithmName()),
+			slog.String("sep", s.sep),
+			slog.String("fingerprint", s.Fingerprint()))
+	} else {
+		attrs = append(attrs,
+			slog.String("backend", fmt.Sprintf("%T", s.backend)),
+			slog.String("sep", s.sep))
+	}
+	return description{typ: typ, attrs: attrs}
+}
+
+func (s Signer) String() string {
+	return s.describe("Signer").String()
+}
+
+func (s Signer) GoString() string {
+	return s.describe("Signer").String()
+}
+
+func (s Signer) Format(f fmt.State, verb rune) {
+	s.describe("Signer").Format(f, verb)
+}
+
+func (s Signer) LogValue() slog.Value {
+	return s.describe("Signer").LogValue()
+}
+
+func (s TimestampSigner) String() string {
+	return s.describe("TimestampSigner").String()
+}
+
+func (s TimestampSigner) GoString() string {
+	return s.describe("TimestampSigner").String()
+}
+
+func (s TimestampSigner) Format(f fmt.State, verb rune) {
+	s.describe("TimestampSigner").Format(f, verb)
+}
+
+func (s TimestampSigner) LogValue() slog.Value {
+	return s.describe("TimestampSigner").LogValue()
+}
+
+func (s URLSafeSerializer) String() string {
+	return s.describe("URLSafeSerializer").String()
+}
+
+func (s URLSafeSerializer) GoString() string {
+	return s.describe("URLSafeSerializer").String()
+}
+
+func (s URLSafeSerializer) Format(f fmt.State, verb rune) {
+	s.describe("URLSafeSerializer").Format(f, verb)
+}
+
+func (s URLSafeSerializer) LogValue() slog.Value {
+	return s.describe("URLSafeSerializer").LogValue()
+}
+
+func (s URLSafeTimedSerializer) String() string {
+	return s.describe("URLSafeTimedSerializer").String()
+}
+
+func (s URLSafeTimedSerializer) GoString() string {
+	return s.describe("URLSafeTimedSerializer").String()
+}
+
+func (s URLSafeTimedSerializer) Format(f fmt.State, verb rune) {
+	s.describe("URLSafeTimedSerializer").Format(f, verb)
+}
+
+func (s URLSafeTimedSerializer) LogValue() slog.Value {
+	return s.describe("URLSafeTimedSerializer").LogValue()
+}
+
+func (s OneTimeSerializer) String() string {
+	return s.serializer.describe("OneTimeSerializer").String()
+}
+
+func (s OneTimeSerializer) GoString() string {
+	return s.serializer.describe("OneTimeSerializer").String()
+}
+
+func (s OneTimeSerializer) Format(f fmt.State, verb rune) {
+	s.serializer.describe("OneTimeSerializer").Format(f, verb)
+}
+
+func (s OneTimeSerializer) LogValue() slog.Value {
+	return s.serializer.describe("OneTimeSerializer").LogValue()
+}
+
+func (s BackendSigner) String() string {
+	return s.signer().describe("BackendSigner").String()
+}
+
+func (s BackendSigner) GoString() string {
+	return s.signer().describe("BackendSigner").String()
+}
+
+func (s BackendSigner) Format(f fmt.State, verb rune) {
+	s.signer().describe("BackendSigner").Format(f, verb)
+}
+
+func (s BackendSigner) LogValue() slog.Value {
+	return s.signer().describe("BackendSigner").LogValue()
+}
+
+func (s BackendTimestampSigner) String() string {
+	return s.signer().describe("BackendTimestampSigner").String()
+}
+
+func (s BackendTimestampSigner) GoString() string {
+	return s.signer().describe("BackendTimestampSigner").String()
+}
+
+func (s BackendTimestampSigner) Format(f fmt.State, verb rune) {
+	s.signer().describe("BackendTimestampSigner").Format(f, verb)
+}
+
+func (s BackendTimestampSigner) LogValue() slog.Value {
+	return s.signer().describe("BackendTimestampSigner").LogValue()
+}
+
+func (s BackendURLSafeSerializer) String() string {
+	return s.serializer().describe("BackendURLSafeSerializer").String()
+}
+
+func (s BackendURLSafeSerializer) GoString() string {
+	return s.serializer().describe("BackendURLSafeSerializer").String()
+}
+
+func (s BackendURLSafeSerializer) Format(f fmt.State, verb rune) {
+	s.serializer().describe("BackendURLSafeSerializer").Format(f, verb)
+}
+
+func (s BackendURLSafeSerializer) LogValue() slog.Value {
+	return s.serializer().describe("BackendURLSafeSerializer").LogValue()
+}
+
+func (s BackendURLSafeTimedSerializer) String() string {
+	return s.serializer().describe("BackendURLSafeTimedSerializer").String()
+}
+
+func (s BackendURLSafeTimedSerializer) GoString() string {
+	return s.serializer().describe("BackendURLSafeTimedSerializer").String()
+}
+
+func (s BackendURLSafeTimedSerializer) Format(f fmt.State, verb rune) {
+	s.serializer().describe("BackendURLSafeTimedSerializer").Format(f, verb)
+}
+
+func (s BackendURLSafeTimedSerializer) LogValue() slog.Value {
+	return s.serializer().describe("BackendURLSafeTimedSerializer").LogValue()
+}
+
+func (s JSONWebSignatureSerializer) String() string {
+	return s.describe("JSONWebSignatureSerializer").String()
+}
+
+func (s JSONWebSignatureSerializer) GoString() string {
+	return s.describe("JSONWebSignatureSerializer").String()
+}
+
+func (s JSONWebSignatureSerializer) Format(f fmt.State, verb rune) {
+	s.describe("JSONWebSignatureSerializer").Format(f, verb)
+}
+
+func (s JSONWebSignatureSerializer) LogValue() slog.Value {
+	return s.describe("JSONWebSignatureSerializer").LogValue()
+}
+
+func (s TimedJSONWebSignatureSerializer) String() string {
+	return s.describe("TimedJSONWebSignatureSerializer").String()
+}
+
+func (s TimedJSONWebSignatureSerializer) GoString() string {
+	return s.describe("TimedJSONWebSignatureSerializer").String()
+}
+
+func (s TimedJSONWebSignatureSerializer) Format(f fmt.State, verb rune) {
+	s.describe("TimedJSONWebSignatureSerializer").Format(f, verb)
+}
+
+func (s TimedJSONWebSignatureSerializer) LogValue() slog.Value {
+	return s.describe("TimedJSONWebSignatureSerializer").LogValue()
+}
+
+// describe returns the non-secret metadata of the serializer.
+func (s EncryptedSerializer) describe() description {
+	return description{typ: "EncryptedSerializer", attrs: []slog.Attr{
+		slog.String("algorithm", "AES-256-GCM"),
+		slog.Int("keys", len(s.aeads)),
+		slog.String("fingerprint", s.fingerprint),
+	}}
+}
+
+func (s EncryptedSerializer) String() string {
+	return s.describe().String()
+}
+
+func (s EncryptedSerializer) GoString() string {
+	return s.describe().String()
+}
+
+func (s EncryptedSerializer) Format(f fmt.State, verb rune) {
+	s.describe().Format(f, verb)
+}
+
+func (s EncryptedSerializer) LogValue() slog.Value {
+	return s.describe().LogValue()
+}
+
+// describe returns the non-secret metadata of the key.
+func (k Key) describe() description {
+	status := k.Status
+	if status == "" {
+		status = KeyActive
+	}
+	attrs := []slog.Attr{slog.String("id", k.ID), slog.String("status", string(status))}
+	for _, t := range []struct {
+		name string
+		time time.Time
+	}{{"created", k.Created}, {"not_before", k.NotBefore}, {"not_after", k.NotAfter}, {"retire_at", k.RetireAt}} {
+		if !t.time.IsZero() {
+			attrs = append(attrs, slog.Time(t.name, t.time))
+		}
+	}
+	return description{typ: "Key", attrs: attrs}
+}
+
+func (k Key) String() string {
+	return k.describe().String()
+}
+
+func (k Key) GoString() string {
+	return k.describe().String()
+}
+
+func (k Key) Format(f fmt.State, verb rune) {
+	k.describe().Format(f, verb)
+}
+
+func (k Key) LogValue() slog.Value {
+	return k.describe().LogValue()
+}
+
+// describe returns the non-secret metadata of the key.
+func (k KeyringFileKey) describe() description {
+	d := Key{ID: k.ID, Status: k.Status, Created: k.Created, NotBefore: k.NotBefore, NotAfter: k.NotAfter, RetireAt: k.RetireAt}.describe()
+	d.typ = "KeyringFileKey"
+	if k.Derivation != "" {
+		d.attrs = append(d.attrs, slog.String("derivation", k.Derivation))
+	}
+	if k.Digest != "" {
+		d.attrs = append(d.attrs, slog.String("digest", k.Digest))
+	}
+	return d
+}
+
+func (k KeyringFileKey) String() string {
+	return k.describe().String()
+}
+
+func (k KeyringFileKey) GoString() string {
+	return k.describe().String()
+}
+
+func (k KeyringFileKey) Format(f fmt.State, verb rune) {
+	k.describe().Format(f, verb)
+}
+
+func (k KeyringFileKey) LogValue() slog.Value {
+	return k.describe().LogValue()
+}
+
+// describe returns the non-secret metadata of the keyring.
+func (k *Keyring) describe() description {
+	return description{typ: "Keyring", attrs: []slog.Attr{
+		slog.String("algorithm", k.algorithmName()),
+		slog.Int("keys", len(k.keys.Load().keys)),
+		slog.String("fingerprint", k.fingerprint()),
+	}}
+}
+
+func (k *Keyring) String() string {
+	return k.describe().String()
+}
+
+func (k *Keyring) GoString() string {
+	return k.describe().String()
+}
+
+func (k *Keyring) Format(f fmt.State, verb rune) {
+	k.describe().Format(f, verb)
+}
+
+func (k *Keyring) LogValue() slog.Value {
+	return k.describe().LogValue()
+}
+
+// fingerprint returns a non-reversible identifier of the Fernet's keys.
+func (f Fernet) fingerprint() string {
+	return keyFingerprint("fernet", f.signingKey, f.encryptionKey)
+}
+
+// describe returns the non-secret metadata of the Fernet.
+func (f Fernet) describe() description {
+	return description{typ: "Fernet", attrs: []slog.Attr{
+		slog.String("algorithm", "AES-128-CBC HMAC-SHA256"),
+		slog.String("fingerprint", f.fingerprint()),
+	}}
+}
+
+func (f Fernet) String() string {
+	return f.describe().String()
+}
+
+func (f Fernet) GoString() string {
+	return f.describe().String()
+}
+
+func (f Fernet) Format(s fmt.State, verb rune) {
+	f.describe().Format(s, verb)
+}
+
+func (f Fernet) LogValue() slog.Value {
+	return f.describe().LogValue()
+}
+
+// describe returns the non-secret metadata of the MultiFernet.
+func (m MultiFernet) describe() description {
+	parts := make([][]byte, len(m.fernets))
+	for i, f := range m.fernets {
+		parts[i] = []byte(f.fingerprint())
+	}
+	return description{typ: "MultiFernet", attrs: []slog.Attr{
+		slog.String("algorithm", "AES-128-CBC HMAC-SHA256"),
+		slog.Int("keys", len(m.fernets)),
+		slog.String("fingerprint", keyFingerprint("multi-fernet", parts...)),
+	}}
+}
+
+func (m MultiFernet) String() string {
+	return m.describe().String()
+}
+
+func (m MultiFernet) GoString() string {
+	return m.describe().String()
+}
+
+func (m MultiFernet) Format(f fmt.State, verb rune) {
+	m.describe().Format(f, verb)
+}
+
+func (m MultiFernet) LogValue() slog.Value {
+	return m.describe().LogValue()
+}
+
+// describe returns the non-secret metadata of the keyring file.
+func (f KeyringFile) describe() description {
+	ids := make([]string, len(f.Keys))
+	for i, k := range f.Keys {
+		ids[i] = k.ID
+	}
+	return description{typ: "KeyringFile", attrs: []slog.Attr{
+		slog.Int("version", f.Version),
+		slog.String("keys", strings.Join(ids, ",")),
+	}}
+}
+
+func (f KeyringFile) String() string {
+	return f.describe().String()
+}
+
+func (f KeyringFile) GoString() string {
+	return f.describe().String()
+}
+
+func (f KeyringFile) Format(s fmt.State, verb rune) {
+	f.describe().Format(s, verb)
+}
+
+func (f KeyringFile) LogValue() slog.Value {
+	return f.describe().LogValue()
+}
@@ -0,0 +1,188 @@
+package itsdangerous_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestFormatHidesKey(t *testing.T) {
+	fernetKey := make([]byte, 32)
+	for i := range fernetKey {
+		fernetKey[i] = byte(i * 7)
+	}
+	var secrets []string
+	for _, key := range [][]byte{signerKey(), fernetKey[:16], fernetKey[16:]} {
+		secrets = append(secrets, hex.EncodeToString(key), base64.RawURLEncoding.EncodeToString(key), fmt.Sprint(key))
+	}
+	secrets = append(secrets, "secret_key", base64.URLEncoding.EncodeToString(fernetKey))
+
+	signer := itsdangerous.NewSigner("secret_key", "salt")
+	jws, err := itsdangerous.NewJSONWebSignatureSerializer("secret_key", "salt", "HS256")
+	if err != nil {
+		t.Fatalf("NewJSONWebSignatureSerializer returned error: %s", err)
+	}
+	timedJWS, err := itsdangerous.NewTimedJSONWebSignatureSerializer("secret_key", "salt", "", 0)
+	if err != nil {
+		t.Fatalf("NewTimedJSONWebSignatureSerializer returned error: %s", err)
+	}
+	encrypted, err := itsdangerous.NewEncryptedSerializer([]string{"secret_key"}, "salt")
+	if err != nil {
+		t.Fatalf("NewEncryptedSerializer returned error: %s", err)
+	}
+	fernet, err := itsdangerous.NewFernet(base64.URLEncoding.EncodeToString(fernetKey))
+	if err != nil {
+		t.Fatalf("NewFernet returned error: %s", err)
+	}
+	multiFernet, err := itsdangerous.NewMultiFernet(fernet)
+	if err != nil {
+		t.Fatalf("NewMultiFernet returned error: %s", err)
+	}
+	keyring, err := itsdangerous.NewKeyring([]itsdangerous.Key{{ID: "current", Secret: "secret_key"}}, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	keyringSigner, err := itsdangerous.NewSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithKeyring returned error: %s", err)
+	}
+	keyringTimestampSigner, err := itsdangerous.NewTimestampSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewTimestampSignerWithKeyring returned error: %s", err)
+	}
+	keyringFile := itsdangerous.KeyringFile{Version: itsdangerous.KeyringFileVersion,
+		Keys: []itsdangerous.KeyringFileKey{{ID: "current", Secret: "secret_key", Digest: "sha256"}}}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+		// nested values are only formatted by fmt; slog doesn't resolve
+		// LogValuer in struct fields.
+		nested        bool
+		noFingerprint bool
+	}{
+		{name: "Signer", value: signer, expected: `algorithm:"HMAC-SHA1" sep:"."`},
+		{name: "TimestampSigner", value: itsdangerous.NewTimestampSigner("secret_key", "salt"), expected: `algorithm:"HMAC-SHA1"`},
+		{name: "URLSafeSerializer", value: itsdangerous.NewURLSafeSerializer("secret_key", "salt")},
+		{name: "URLSafeTimedSerializer", value: itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")},
+		{name: "OneTimeSerializer", value: itsdangerous.NewOneTimeSerializer("secret_key", "salt", nil)},
+		{name: "JSONWebSignatureSerializer", value: jws, expected: `algorithm:"HMAC-SHA256"`},
+		{name: "TimedJSONWebSignatureSerializer", value: timedJWS, expected: `algorithm:"HMAC-SHA512"`},
+		{name: "EncryptedSerializer", value: encrypted, expected: `algorithm:"AES-256-GCM" keys:1`},
+		{name: "Signer", value: struct{ S itsdangerous.Signer }{*signer}, nested: true},
+		{name: "Fernet", value: fernet, expected: `algorithm:"AES-128-CBC HMAC-SHA256"`},
+		{name: "Fernet", value: *fernet},
+		{name: "MultiFernet", value: multiFernet, expected: `keys:1`},
+		{name: "Keyring", value: keyring, expected: `algorithm:"HMAC-SHA1" keys:1`},
+		{name: "Key", value: keyring.Keys()[0], expected: `id:"current" status:"active"`, noFingerprint: true},
+		{name: "Key", value: keyring.Keys(), nested: true, noFingerprint: true},
+		{name: "KeyringFile", value: keyringFile, expected: `version:1 keys:"current"`, noFingerprint: true},
+		{name: "KeyringFileKey", value: keyringFile.Keys[0], expected: `digest:"sha256"`, noFingerprint: true},
+		{name: "LocalBackend", value: &itsdangerous.LocalBackend{Key: signerKey()}, expected: `algorithm:"HMAC-SHA1"`},
+		{name: "LocalBackend", value: itsdangerous.LocalBackend{Key: signerKey(), Algorithm: &itsdangerous.HMACAlgorithm{DigestMethod: sha256.New}},
+			expected: `algorithm:"HMAC-SHA256"`},
+		{name: "BackendSigner", value: keyringSigner, expected: `algorithm:"HMAC-SHA1"`},
+		{name: "BackendTimestampSigner", value: keyringTimestampSigner},
+		{name: "BackendURLSafeSerializer", value: itsdangerous.NewURLSafeSerializerWithKeyring(keyring)},
+		{name: "BackendURLSafeTimedSerializer", value: itsdangerous.NewURLSafeTimedSerializerWithKeyring(keyring)},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			slog.New(slog.NewJSONHandler(&logged, nil)).Info("test", "signer", test.value)
+
+			outputs := map[string]string{"log": logged.String()}
+			for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q"} {
+				outputs[verb] = fmt.Sprintf(verb, test.value)
+			}
+			for name, output := range outputs {
+				for _, secret := range secrets {
+					if strings.Contains(output, secret) {
+						t.Errorf("%s output contains secret %s: %s", name, secret, output)
+					}
+				}
+				if test.nested && name == "log" {
+					continue
+				}
+				if !strings.Contains(output, test.name) || !test.noFingerprint && !strings.Contains(output, "fingerprint") {
+					t.Errorf("%s output missing type or fingerprint: %s", name, output)
+				}
+			}
+			if !strings.Contains(outputs["%v"], test.expected) {
+				t.Errorf("%%v output got %s; want it to contain %s", outputs["%v"], test.expected)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := itsdangerous.NewSigner("secret_key", "salt").Fingerprint()
+	if len(fingerprint) != 16 {
+		t.Errorf("Fingerprint() got %s; want 16 hex characters", fingerprint)
+	}
+	if actual := fmt.Sprint(itsdangerous.NewSigner("secret_key", "salt")); !strings.Contains(actual, fingerprint) {
+		t.Errorf("Sprint got %s; want it to contain fingerprint %s", actual, fingerprint)
+	}
+
+	same, err := itsdangerous.NewSignerWithBackend(&itsdangerous.LocalBackend{Key: signerKey()}, "~")
+	if err != nil {
+		t.Fatalf("NewSignerWithBackend returned error: %s", err)
+	}
+	// An algorithm of another type which signs the same way has the same
+	// fingerprint.
+	wrapped, err := itsdangerous.NewSignerWithBackend(&itsdangerous.LocalBackend{
+		Key:       signerKey(),
+		Algorithm: struct{ itsdangerous.SigningAlgorithm }{&itsdangerous.HMACAlgorithm{DigestMethod: sha1.New}},
+	}, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithBackend returned error: %s", err)
+	}
+	for name, s := range map[string]interface{ Fingerprint() string }{
+		"NewSigner":          itsdangerous.NewSigner("secret_key", "salt"),
+		"NewTimestampSigner": &itsdangerous.NewTimestampSigner("secret_key", "salt").Signer,
+		"LocalBackend":       same,
+		"wrapped algorithm":  wrapped,
+	} {
+		if actual := s.Fingerprint(); actual != fingerprint {
+			t.Errorf("%s Fingerprint() got %s; want %s", name, actual, fingerprint)
+		}
+	}
+
+	differentSalt := itsdangerous.NewSigner("secret_key", "other_salt")
+	differentDigest, err := itsdangerous.NewSignerWithOptions("secret_key", "salt", "", "", sha256.New, nil)
+	if err != nil {
+		t.Fatalf("NewSignerWithOptions returned error: %s", err)
+	}
+	differentDerivation, err := itsdangerous.NewSignerWithOptions("secret_key", "salt", "", "concat", nil, nil)
+	if err != nil {
+		t.Fatalf("NewSignerWithOptions returned error: %s", err)
+	}
+	for name, s := range map[string]interface{ Fingerprint() string }{
+		"salt":       differentSalt,
+		"digest":     differentDigest,
+		"derivation": differentDerivation,
+		"keyring":    newKeyringSigner(t, itsdangerous.Key{ID: "current", Secret: "secret_key"}),
+	} {
+		if actual := s.Fingerprint(); actual == fingerprint || actual == "" {
+			t.Errorf("Fingerprint() with different %s got %s", name, actual)
+		}
+	}
+
+	remote, err := itsdangerous.NewSignerWithBackend(&remoteBackend{}, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithBackend returned error: %s", err)
+	}
+	if actual := remote.Fingerprint(); actual != "" {
+		t.Errorf("Fingerprint() with remote backend got %s; want empty", actual)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/junohq/go-itsdangerous
 
 go 1.22
+
+require golang.org/x/crypto v0.33.0
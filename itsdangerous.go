@@ -12,6 +12,10 @@ import (
 	"time"
 )
 
+// The characters that may appear in encoded output. A separator made up only of
+// these could be confused with part of a signature or timestamp.
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_="
+
 // Encodes a single string. The resulting string is safe for putting into URLs.
 func base64Encode(src []byte) string {
 	return base64.RawURLEncoding.EncodeToString(src)
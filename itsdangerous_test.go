@@ -1,7 +1,12 @@
 package itsdangerous
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -35,3 +40,74 @@ func TestBase64(t *testing.T) {
 		})
 	}
 }
+
+func TestDeriveKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		salt       string
+		derivation string
+		expected   string
+	}{
+		// RFC 5869 test case 3, truncated to the digest size
+		{name: "hkdf", secret: strings.Repeat("\x0b", 22), derivation: "hkdf",
+			expected: "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d"},
+		{name: "pbkdf2", secret: "secret_key", salt: "salt", derivation: "pbkdf2",
+			expected: "40fb1a7525c0994d0d6d4a85b26cd8d815e7d6ea425ac98925572fd6fe11f583"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			key, err := deriveKey(test.secret, test.salt, test.derivation, sha256.New)
+			if err != nil {
+				t.Fatalf("deriveKey returned error: %s", err)
+			}
+			if actual := hex.EncodeToString(key); actual != test.expected {
+				t.Errorf("deriveKey got %s; want %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestKeyringReloadUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("old secret_key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := NewKeyring([]Key{{ID: "other", Secret: "other_secret"}}, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+
+	if err := keyring.Reload(path); err != nil {
+		t.Fatalf("Reload returned error: %s", err)
+	}
+	loaded := keyring.keys.Load()
+	if err := keyring.Reload(path); err != nil {
+		t.Fatalf("Reload returned error: %s", err)
+	}
+	if keyring.keys.Load() != loaded {
+		t.Errorf("Reload of unchanged file derived the keys again")
+	}
+
+	// Keys set otherwise are replaced even if the file is unchanged.
+	if err := keyring.SetKeys([]Key{{ID: "other", Secret: "other_secret"}}); err != nil {
+		t.Fatalf("SetKeys returned error: %s", err)
+	}
+	if err := keyring.Reload(path); err != nil {
+		t.Fatalf("Reload returned error: %s", err)
+	}
+	if keys := keyring.Keys(); len(keys) != 1 || keys[0].ID != "old" {
+		t.Errorf("Reload after SetKeys got keys %v; want old", keys)
+	}
+
+	if err := os.WriteFile(path, []byte("old secret_key\ncurrent new_secret_key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Reload(path); err != nil {
+		t.Fatalf("Reload returned error: %s", err)
+	}
+	if keys := keyring.Keys(); len(keys) != 2 {
+		t.Errorf("Reload of changed file got keys %v; want old and current", keys)
+	}
+}
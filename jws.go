@@ -0,0 +1,225 @@
+package itsdangerous
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+// jwsAlgorithms are the digests for the JWS algorithms supported by Python
+// itsdangerous. The "none" algorithm is deliberately not supported.
+var jwsAlgorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// JSONWebSignatureSerializer serializes values to JSON Web Signatures in
+// compact serialization, compatible with the JSONWebSignatureSerializer
+// removed in Python itsdangerous 2.1. It exists to read and write tokens for
+// systems that still use that format; new code should prefer
+// URLSafeSerializer.
+type JSONWebSignatureSerializer struct {
+	Signer
+	algorithmName string
+}
+
+// NewJSONWebSignatureSerializer creates a new JSONWebSignatureSerializer with
+// the given secret, salt and algorithm, one of HS256, HS384 or HS512. An
+// empty algorithm defaults to HS512. As in Python, an empty salt means the
+// secret is used as the key directly.
+func NewJSONWebSignatureSerializer(secret, salt, algorithmName string) (*JSONWebSignatureSerializer, error) {
+	if algorithmName == "" {
+		algorithmName = "HS512"
+	}
+	digest, ok := jwsAlgorithms[algorithmName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported JWS algorithm %q", algorithmName)
+	}
+	derivation := ""
+	if salt == "" {
+		derivation = "none"
+	}
+	s, err := NewSignerWithOptions(secret, salt, ".", derivation, nil, &HMACAlgorithm{DigestMethod: digest})
+	if err != nil {
+		return nil, err
+	}
+	return &JSONWebSignatureSerializer{Signer: *s, algorithmName: algorithmName}, nil
+}
+
+func (s *JSONWebSignatureSerializer) Marshal(value interface{}) (string, error) {
+	return s.MarshalWithHeader(value, nil)
+}
+
+// MarshalWithHeader works like Marshal but adds the given fields to the JWS
+// header. The alg field is always set to the serializer's algorithm.
+func (s *JSONWebSignatureSerializer) MarshalWithHeader(value interface{}, header map[string]interface{}) (string, error) {
+	h := make(map[string]interface{}, len(header)+1)
+	for k, v := range header {
+		h[k] = v
+	}
+	h["alg"] = s.algorithmName
+
+	jsonHeader, err := json.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("error JSON marshalling header: %w", err)
+	}
+	jsonPayload, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("error JSON marshalling payload: %w", err)
+	}
+
+	return s.Signer.SignContext(context.Background(), base64Encode(jsonHeader)+"."+base64Encode(jsonPayload))
+}
+
+func (s *JSONWebSignatureSerializer) Unmarshal(signed string, value interface{}) error {
+	_, err := s.UnmarshalWithHeader(signed, value)
+	return err
+}
+
+// UnmarshalWithHeader works like Unmarshal but also returns the verified JWS
+// header. Numbers in the header are returned as json.Number.
+func (s *JSONWebSignatureSerializer) UnmarshalWithHeader(signed string, value interface{}) (map[string]interface{}, error) {
+	// Check the algorithm before anything else so unsigned tokens are
+	// rejected explicitly rather than as a signature mismatch.
+	header, err := UnverifiedJWSHeader(signed)
+	if err != nil {
+		return nil, err
+	}
+	if alg, _ := header["alg"].(string); alg == "none" {
+		return nil, InvalidSignatureError{errors.New(`JWS algorithm "none" is not allowed`)}
+	}
+
+	encoded, err := s.Signer.Unsign(signed)
+	if err != nil {
+		return nil, err
+	}
+	if alg, _ := header["alg"].(string); alg != s.algorithmName {
+		return nil, InvalidSignatureError{fmt.Errorf("JWS algorithm %q does not match %q", alg, s.algorithmName)}
+	}
+
+	_, encodedPayload, _ := strings.Cut(encoded, ".")
+	jsonPayload, err := base64Decode(encodedPayload)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(jsonPayload, value); err != nil {
+		return nil, fmt.Errorf("error JSON unmarshalling payload: %w", err)
+	}
+	return header, nil
+}
+
+// UnverifiedJWSHeader decodes the header of a JWS token without checking its
+// signature. The result must not be trusted; it is only useful for
+// inspecting a token, eg to pick which serializer should verify it.
+func UnverifiedJWSHeader(signed string) (map[string]interface{}, error) {
+	encodedHeader, _, ok := strings.Cut(signed, ".")
+	if !ok {
+		return nil, InvalidSignatureError{errors.New("no . found in value")}
+	}
+	jsonHeader, err := base64Decode(encodedHeader)
+	if err != nil {
+		return nil, InvalidSignatureError{err}
+	}
+
+	var header map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(jsonHeader))
+	d.UseNumber()
+	if err := d.Decode(&header); err != nil || header == nil {
+		return nil, InvalidSignatureError{errors.New("JWS header is not a JSON object")}
+	}
+	return header, nil
+}
+
+// TimedJSONWebSignatureSerializer works like JSONWebSignatureSerializer but
+// adds iat and exp fields to the header, compatible with the
+// TimedJSONWebSignatureSerializer removed in Python itsdangerous 2.1.
+type TimedJSONWebSignatureSerializer struct {
+	JSONWebSignatureSerializer
+
+	// ExpiresIn is how long after signing tokens expire.
+	ExpiresIn time.Duration
+}
+
+// NewTimedJSONWebSignatureSerializer creates a new
+// TimedJSONWebSignatureSerializer. An expiresIn of zero defaults to one hour,
+// matching Python.
+func NewTimedJSONWebSignatureSerializer(secret, salt, algorithmName string, expiresIn time.Duration) (*TimedJSONWebSignatureSerializer, error) {
+	s, err := NewJSONWebSignatureSerializer(secret, salt, algorithmName)
+	if err != nil {
+		return nil, err
+	}
+	if expiresIn == 0 {
+		expiresIn = time.Hour
+	}
+	return &TimedJSONWebSignatureSerializer{JSONWebSignatureSerializer: *s, ExpiresIn: expiresIn}, nil
+}
+
+func (s *TimedJSONWebSignatureSerializer) Marshal(value interface{}) (string, error) {
+	return s.MarshalWithHeader(value, nil)
+}
+
+// MarshalWithHeader works like Marshal but adds the given fields to the JWS
+// header. The alg, iat and exp fields are always set by the serializer.
+func (s *TimedJSONWebSignatureSerializer) MarshalWithHeader(value interface{}, header map[string]interface{}) (string, error) {
+	h := make(map[string]interface{}, len(header)+2)
+	for k, v := range header {
+		h[k] = v
+	}
+	now := getTimestamp()
+	h["iat"] = now
+	h["exp"] = now + int64(s.ExpiresIn.Seconds())
+
+	return s.JSONWebSignatureSerializer.MarshalWithHeader(value, h)
+}
+
+func (s *TimedJSONWebSignatureSerializer) Unmarshal(signed string, value interface{}) error {
+	_, err := s.UnmarshalWithHeader(signed, value)
+	return err
+}
+
+// UnmarshalWithHeader works like Unmarshal but also returns the verified JWS
+// header.
+func (s *TimedJSONWebSignatureSerializer) UnmarshalWithHeader(signed string, value interface{}) (map[string]interface{}, error) {
+	header, err := s.JSONWebSignatureSerializer.UnmarshalWithHeader(signed, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := header["exp"]; !ok {
+		return nil, InvalidSignatureError{errors.New("missing expiry date")}
+	}
+	exp, err := jwsIntDate(header["exp"])
+	if err != nil {
+		return nil, err
+	}
+	iat := exp
+	if _, ok := header["iat"]; ok {
+		if iat, err = jwsIntDate(header["iat"]); err != nil {
+			return nil, err
+		}
+	}
+
+	if now := getTimestamp(); exp < now {
+		return nil, signatureExpired(time.Duration(now-iat)*time.Second, time.Duration(exp-iat)*time.Second)
+	}
+	return header, nil
+}
+
+// jwsIntDate converts a header field to a non-negative number of seconds.
+func jwsIntDate(v interface{}) (int64, error) {
+	n, ok := v.(json.Number)
+	if ok {
+		if i, err := n.Int64(); err == nil && i >= 0 {
+			return i, nil
+		}
+	}
+	return 0, InvalidSignatureError{fmt.Errorf("JWS date %v is not an IntDate", v)}
+}
@@ -0,0 +1,448 @@
+package itsdangerous
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Key is one secret in a Keyring.
+type Key struct {
+	// ID identifies the key in signatures. It must be 1 to 255 characters
+	// from the URL-safe base64 alphabet.
+	ID     string
+	Secret string
+	// Status controls whether the key is used to sign and verify. The zero
+	// value is KeyActive.
+	Status KeyStatus
+	// Created is when the key was created. It is informational only.
+	Created time.Time
+
+	// Derivation and Digest, if set, override the key derivation and digest
+	// of the keyring for this key.
+	Derivation KeyDerivation
+	Digest     func() hash.Hash
+
+	// NotBefore and NotAfter, if set, bound the period in which the key is
+	// active. The keyring only signs with active keys, and TimestampSigner
+	// rejects tokens whose timestamp falls outside the window of the key
+	// which signed them.
+	NotBefore, NotAfter time.Time
+	// RetireAt, if set, is when the key stops being accepted at all.
+	// Signatures made by a retired key are rejected with a RetiredKeyError.
+	RetireAt time.Time
+}
+
+// KeyStatus is the status of a Key in a Keyring.
+type KeyStatus string
+
+const (
+	// KeyActive keys are used to sign and verify.
+	KeyActive KeyStatus = "active"
+	// KeyVerifyOnly keys are only used to verify, as when they are being
+	// rotated out.
+	KeyVerifyOnly KeyStatus = "verify-only"
+	// KeyRetired keys are not used at all. Signatures made by them are
+	// rejected with a RetiredKeyError.
+	KeyRetired KeyStatus = "retired"
+)
+
+// active reports whether the key may sign at the given time.
+func (k *Key) active(t time.Time) bool {
+	return (k.Status == "" || k.Status == KeyActive) &&
+		(k.NotBefore.IsZero() || !t.Before(k.NotBefore)) &&
+		(k.NotAfter.IsZero() || !t.After(k.NotAfter)) &&
+		!k.retired(t)
+}
+
+// signable reports whether the key is active at the given time or will become
+// active later.
+func (k *Key) signable(t time.Time) bool {
+	if k.NotBefore.After(t) {
+		t = k.NotBefore
+	}
+	return k.active(t)
+}
+
+// retired reports whether the key is retired at the given time.
+func (k *Key) retired(t time.Time) bool {
+	return k.Status == KeyRetired || !k.RetireAt.IsZero() && !t.Before(k.RetireAt)
+}
+
+// Keyring is a SigningBackend holding several keys, to allow rotating
+// secrets. It signs with the newest active key, recording its ID in the
+// signature so verification can go straight to the right key.
+//
+// Signatures without a key ID, as made by a Signer with the same secret and
+// salt, are still accepted by trying each key in turn. Signatures naming a key
+// which is not in the keyring are rejected with an UnknownKeyError.
+// Signatures with a key ID are not understood by Python itsdangerous.
+//
+// The keys can be replaced at any time with SetKeys, or reloaded from files
+// with Reload and Poll, including while the keyring is in use. Signers using
+// the keyring see the new keys immediately.
+type Keyring struct {
+	salt       string
+	derivation KeyDerivation
+	digest     func() hash.Hash
+	algorithm  SigningAlgorithm
+
+	keys atomic.Pointer[keyset]
+	// stats maps key IDs to their *keyStats. It is kept across changes to
+	// the keys, and shared with bound keyrings.
+	stats *sync.Map
+
+	// reloadMu guards reloaded, the hash of the files the keys were last
+	// loaded from by Reload, which is cleared when the keys are set
+	// otherwise.
+	reloadMu sync.Mutex
+	reloaded []byte
+}
+
+// keyset is an immutable set of keys with their derived keys, which is
+// swapped as a whole when the keys change.
+type keyset struct {
+	// keys are ordered newest first, which is the order they are tried in.
+	keys []keyringKey
+}
+
+type keyringKey struct {
+	Key
+	derived   []byte
+	algorithm SigningAlgorithm
+}
+
+// NewKeyring creates a new Keyring with the given keys, ordered oldest to
+// newest, and salt. All other properties will be set to match the Python
+// itsdangerous defaults, as for NewSigner.
+func NewKeyring(keys []Key, salt string) (*Keyring, error) {
+	return NewKeyringWithOptions(keys, salt, "", nil, nil)
+}
+
+// NewKeyringWithOptions creates a new Keyring allowing overiding the default
+// properties, as for NewSignerWithOptions.
+func NewKeyringWithOptions(keys []Key, salt, derivation string, digest func() hash.Hash, algo SigningAlgorithm) (*Keyring, error) {
+	if salt == "" {
+		salt = "itsdangerous.Signer"
+	}
+	if derivation == "" {
+		derivation = "django-concat"
+	}
+	d, err := KeyDerivationByName(derivation)
+	if err != nil {
+		return nil, err
+	}
+	if digest == nil {
+		digest = sha1.New
+	}
+	if algo == nil {
+		algo = &HMACAlgorithm{DigestMethod: digest}
+	}
+
+	k := &Keyring{
+		salt:       salt,
+		derivation: d,
+		digest:     digest,
+		algorithm:  algo,
+		stats:      &sync.Map{},
+	}
+	if err := k.SetKeys(keys); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// SetKeys atomically replaces the keys, ordered oldest to newest. If the keys
+// are invalid, including when none is active or will become active, an error
+// is returned and the current keys are kept.
+func (k *Keyring) SetKeys(keys []Key) error {
+	k.reloadMu.Lock()
+	defer k.reloadMu.Unlock()
+	k.reloaded = nil
+	return k.setKeys(keys)
+}
+
+func (k *Keyring) setKeys(keys []Key) error {
+	ks, err := k.newKeyset(keys)
+	if err != nil {
+		return err
+	}
+	k.keys.Store(ks)
+	return nil
+}
+
+// Keys returns the current keys, ordered oldest to newest.
+func (k *Keyring) Keys() []Key {
+	ks := k.keys.Load()
+	keys := make([]Key, len(ks.keys))
+	for i, key := range ks.keys {
+		keys[len(keys)-1-i] = key.Key
+	}
+	return keys
+}
+
+// newKeyset validates the given keys and derives their keys.
+func (k *Keyring) newKeyset(keys []Key) (*keyset, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+	ks := &keyset{}
+	seen := make(map[string]bool, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		key := keys[i]
+		if len(key.ID) == 0 || len(key.ID) > 255 || strings.Trim(key.ID, base64Alphabet) != "" {
+			return nil, fmt.Errorf("key ID %q must be 1 to 255 characters from the URL-safe base64 alphabet", key.ID)
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		switch key.Status {
+		case "", KeyActive, KeyVerifyOnly, KeyRetired:
+		default:
+			return nil, fmt.Errorf("key %q has unknown status %q", key.ID, key.Status)
+		}
+		if !key.NotBefore.IsZero() && !key.NotAfter.IsZero() && key.NotAfter.Before(key.NotBefore) {
+			return nil, fmt.Errorf("key %q is not valid after it is valid from", key.ID)
+		}
+		seen[key.ID] = true
+
+		derivation, digest, algorithm := k.derivation, k.digest, k.algorithm
+		if key.Derivation != nil {
+			derivation = key.Derivation
+		}
+		if key.Digest != nil {
+			digest = key.Digest
+			algorithm = &HMACAlgorithm{DigestMethod: digest}
+		}
+		derived, err := derivation.DeriveKey(key.Secret, k.salt, digest)
+		if err != nil {
+			return nil, err
+		}
+		ks.keys = append(ks.keys, keyringKey{Key: key, derived: derived, algorithm: algorithm})
+	}
+
+	if err := ks.checkSignable(NowFunc()); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// checkSignable returns an error if no key is active at t or will become
+// active later.
+func (ks *keyset) checkSignable(t time.Time) error {
+	for i := range ks.keys {
+		if ks.keys[i].signable(t) {
+			return nil
+		}
+	}
+	return errors.New("no key is active or will become active, so the keyring could not sign")
+}
+
+// Sign returns the signature for the given value, prefixed with the length
+// and ID of the newest active key. If no key is active an error is returned.
+func (k *Keyring) Sign(ctx context.Context, value string) ([]byte, error) {
+	key, err := k.keys.Load().signingKey(NowFunc())
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 0, 1+len(key.ID)+64)
+	sig = append(sig, byte(len(key.ID)))
+	sig = append(sig, key.ID...)
+	return append(sig, key.algorithm.GetSignature(key.derived, value)...), nil
+}
+
+// Verify verifies the given signature was made by one of the keys.
+func (k *Keyring) Verify(ctx context.Context, value string, signature []byte) (bool, error) {
+	_, err := k.verifyKey(ctx, value, signature)
+	if errors.As(err, &InvalidSignatureError{}) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// verifyKey verifies the given signature and returns the key which made it.
+func (k *Keyring) verifyKey(ctx context.Context, value string, signature []byte) (*Key, error) {
+	ks := k.keys.Load()
+	now := NowFunc()
+	id, sig, ok := splitKeyID(signature)
+	if ok {
+		for _, key := range ks.keys {
+			if key.ID == id && key.algorithm.VerifySignature(key.derived, value, sig) {
+				return k.verified(&key, now)
+			}
+		}
+	}
+
+	// Fall back to legacy signatures without a key ID.
+	for _, key := range ks.keys {
+		if key.algorithm.VerifySignature(key.derived, value, signature) {
+			return k.verified(&key, now)
+		}
+	}
+
+	if ok && !ks.hasKey(id) {
+		return nil, unknownKey(id)
+	}
+	if ok {
+		return nil, InvalidSignatureError{fmt.Errorf("signature does not match key %q", id)}
+	}
+	return nil, InvalidSignatureError{errors.New("signature does not match")}
+}
+
+// bind returns a keyring whose keys are derived from the current ones and the
+// given data.
+func (k *Keyring) bind(data string) SigningBackend {
+	ks := k.keys.Load()
+	bound := &keyset{keys: make([]keyringKey, len(ks.keys))}
+	for i, key := range ks.keys {
+		bound.keys[i] = keyringKey{
+			Key:       key.Key,
+			derived:   key.algorithm.GetSignature(key.derived, data),
+			algorithm: key.algorithm,
+		}
+	}
+	b := &Keyring{stats: k.stats}
+	b.keys.Store(bound)
+	return b
+}
+
+// signingKey returns the newest key which is active at the given time.
+func (ks *keyset) signingKey(t time.Time) (*keyringKey, error) {
+	for i := range ks.keys {
+		if ks.keys[i].active(t) {
+			return &ks.keys[i], nil
+		}
+	}
+	return nil, errors.New("no key in the keyring is active")
+}
+
+// verified records the use of the key which verified a signature and returns
+// it, unless it is retired.
+func (k *Keyring) verified(key *keyringKey, t time.Time) (*Key, error) {
+	if key.retired(t) {
+		return nil, retiredKey(key.ID)
+	}
+	s, ok := k.stats.Load(key.ID)
+	if !ok {
+		s, _ = k.stats.LoadOrStore(key.ID, &keyStats{})
+	}
+	s.(*keyStats).record(t)
+	verified := key.Key
+	return &verified, nil
+}
+
+func (ks *keyset) hasKey(id string) bool {
+	for _, key := range ks.keys {
+		if key.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// splitKeyID splits a signature into the key ID and the signature made by
+// that key. A signature without a valid key ID reports false.
+func splitKeyID(signature []byte) (string, []byte, bool) {
+	if len(signature) == 0 {
+		return "", nil, false
+	}
+	n := int(signature[0])
+	if n == 0 || len(signature) <= 1+n {
+		return "", nil, false
+	}
+	id := signature[1 : 1+n]
+	if len(bytes.Trim(id, base64Alphabet)) != 0 {
+		return "", nil, false
+	}
+	return string(id), signature[1+n:], true
+}
+
+// KeyStats are the usage statistics of a key in a Keyring.
+type KeyStats struct {
+	// Verified is the number of signatures the key has verified.
+	Verified uint64 `json:"verified"`
+	// LastUsed is when the key last verified a signature, or zero if it
+	// never has.
+	LastUsed time.Time `json:"last_used"`
+}
+
+type keyStats struct {
+	verified atomic.Uint64
+	lastUsed atomic.Int64
+}
+
+func (s *keyStats) record(t time.Time) {
+	s.verified.Add(1)
+	// Only move forwards, in case of concurrent updates.
+	for {
+		last := s.lastUsed.Load()
+		if t.UnixNano() <= last || s.lastUsed.CompareAndSwap(last, t.UnixNano()) {
+			return
+		}
+	}
+}
+
+// Stats returns the usage statistics of the current keys, by key ID. They
+// can be used to tell when nothing is presenting tokens signed by a key, so
+// it can be retired. Statistics are kept in memory only, so are reset when
+// the process restarts.
+func (k *Keyring) Stats() map[string]KeyStats {
+	ks := k.keys.Load()
+	stats := make(map[string]KeyStats, len(ks.keys))
+	for _, key := range ks.keys {
+		var st KeyStats
+		if s, ok := k.stats.Load(key.ID); ok {
+			st.Verified = s.(*keyStats).verified.Load()
+			if last := s.(*keyStats).lastUsed.Load(); last != 0 {
+				st.LastUsed = time.Unix(0, last)
+			}
+		}
+		stats[key.ID] = st
+	}
+	return stats
+}
+
+// StatsVar returns an expvar.Var reporting the result of Stats as JSON, to
+// be published with expvar.Publish. It is returned as a fmt.Stringer, which
+// is the same interface, so that this package doesn't import expvar and
+// register its HTTP handler for programs which don't use it.
+func (k *Keyring) StatsVar() fmt.Stringer {
+	return keyringStatsVar{k}
+}
+
+type keyringStatsVar struct {
+	keyring *Keyring
+}
+
+func (v keyringStatsVar) String() string {
+	b, _ := json.Marshal(v.keyring.Stats())
+	return string(b)
+}
+
+// keyedBackend is implemented by backends which can report the key which
+// verified a signature.
+type keyedBackend interface {
+	verifyKey(ctx context.Context, value string, signature []byte) (*Key, error)
+}
+
+// NewSignerWithKeyring creates a new BackendSigner which signs with the given
+// keyring. Signing fails if none of the keyring's keys is active at the time,
+// eg once they have all passed their NotAfter time.
+func NewSignerWithKeyring(keyring *Keyring, sep string) (*BackendSigner, error) {
+	return NewSignerWithBackend(keyring, sep)
+}
+
+// NewTimestampSignerWithKeyring creates a new BackendTimestampSigner which
+// signs with the given keyring. As with NewSignerWithKeyring, signing fails if
+// none of the keyring's keys is active.
+func NewTimestampSignerWithKeyring(keyring *Keyring, sep string) (*BackendTimestampSigner, error) {
+	return NewTimestampSignerWithBackend(keyring, sep)
+}
@@ -0,0 +1,330 @@
+package itsdangerous
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LoadKeys reads keys from the given path, ordered oldest to newest.
+//
+// If path is a directory, as when secrets are mounted by Kubernetes, each
+// file in it is a key whose ID is the file name and whose secret is the file
+// contents, without trailing newlines. Hidden files are ignored. Keys are
+// ordered by file name, so name them so the newest sorts last, eg by date.
+//
+// If path ends in .json it is a keyring file as described by KeyringFile.
+//
+// Otherwise path is a file containing one key per line, as the key ID and
+// secret separated by whitespace. Blank lines and lines starting with # are
+// ignored.
+func LoadKeys(path string) ([]Key, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadKeysDir(path)
+	}
+	if filepath.Ext(path) == ".json" {
+		f, err := ReadKeyringFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return f.KeyringKeys()
+	}
+	return loadKeysFile(path)
+}
+
+func loadKeysDir(dir string) ([]Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// Stat rather than using the entry so symlinks are followed.
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, Key{ID: entry.Name(), Secret: strings.TrimRight(string(secret), "\r\n")})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func loadKeysFile(path string) ([]Key, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []Key
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected key ID and secret", path, line)
+		}
+		keys = append(keys, Key{ID: fields[0], Secret: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// KeyringFileVersion is the version of the keyring file format understood by
+// ReadKeyringFile.
+const KeyringFileVersion = 1
+
+// KeyringFile is a versioned JSON file storing the keys of a keyring, as
+// managed by the itsdangerous command. Keys are ordered oldest to newest.
+type KeyringFile struct {
+	Version int              `json:"version"`
+	Keys    []KeyringFileKey `json:"keys"`
+}
+
+// KeyringFileKey is a key in a KeyringFile. Derivation is one of the names
+// accepted by NewSignerWithOptions and Digest one of "sha1", "sha224",
+// "sha256", "sha384" or "sha512"; if empty the keyring's defaults are used.
+type KeyringFileKey struct {
+	ID         string    `json:"id"`
+	Created    time.Time `json:"created"`
+	Status     KeyStatus `json:"status"`
+	Secret     string    `json:"secret"`
+	Derivation string    `json:"derivation,omitempty"`
+	Digest     string    `json:"digest,omitempty"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	RetireAt   time.Time `json:"retire_at"`
+}
+
+// MarshalJSON encodes the key, omitting unset times.
+func (k KeyringFileKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID         string     `json:"id"`
+		Created    *time.Time `json:"created,omitempty"`
+		Status     KeyStatus  `json:"status"`
+		Secret     string     `json:"secret"`
+		Derivation string     `json:"derivation,omitempty"`
+		Digest     string     `json:"digest,omitempty"`
+		NotBefore  *time.Time `json:"not_before,omitempty"`
+		NotAfter   *time.Time `json:"not_after,omitempty"`
+		RetireAt   *time.Time `json:"retire_at,omitempty"`
+	}{
+		ID:         k.ID,
+		Created:    optionalTime(k.Created),
+		Status:     k.Status,
+		Secret:     k.Secret,
+		Derivation: k.Derivation,
+		Digest:     k.Digest,
+		NotBefore:  optionalTime(k.NotBefore),
+		NotAfter:   optionalTime(k.NotAfter),
+		RetireAt:   optionalTime(k.RetireAt),
+	})
+}
+
+// optionalTime returns nil for the zero time, so it is omitted from JSON.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// ReadKeyringFile reads and parses the keyring file at path.
+func ReadKeyringFile(path string) (*KeyringFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f KeyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if f.Version != KeyringFileVersion {
+		return nil, fmt.Errorf("%s: unsupported keyring file version %d", path, f.Version)
+	}
+	return &f, nil
+}
+
+// Write writes the keyring file to path, replacing it atomically so readers
+// never see a partial file. The file is only readable by its owner.
+func (f *KeyringFile) Write(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// KeyringKeys returns the keys in the file as Keys for a Keyring.
+func (f *KeyringFile) KeyringKeys() ([]Key, error) {
+	keys := make([]Key, len(f.Keys))
+	for i, k := range f.Keys {
+		keys[i] = Key{
+			ID:        k.ID,
+			Secret:    k.Secret,
+			Status:    k.Status,
+			Created:   k.Created,
+			NotBefore: k.NotBefore,
+			NotAfter:  k.NotAfter,
+			RetireAt:  k.RetireAt,
+		}
+		if k.Derivation != "" {
+			d, err := KeyDerivationByName(k.Derivation)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", k.ID, err)
+			}
+			keys[i].Derivation = d
+		}
+		if k.Digest != "" {
+			digest, err := DigestByName(k.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", k.ID, err)
+			}
+			keys[i].Digest = digest
+		}
+	}
+	return keys, nil
+}
+
+// LoadKeyringFile creates a new Keyring with the keys from the keyring file
+// at path and the given salt.
+func LoadKeyringFile(path, salt string) (*Keyring, error) {
+	f, err := ReadKeyringFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := f.KeyringKeys()
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(keys, salt)
+}
+
+// Reload replaces the keys with those read from path by LoadKeys. If the keys
+// can't be read or are invalid the current keys are kept. If the files are
+// unchanged since the keys were last loaded from them, the keys are kept
+// without deriving them again, which may be slow, eg with pbkdf2, though an
+// error is still returned if none of them can sign any more.
+func (k *Keyring) Reload(path string) error {
+	sum, err := keysSum(path)
+	if err != nil {
+		return err
+	}
+	k.reloadMu.Lock()
+	defer k.reloadMu.Unlock()
+	if bytes.Equal(sum, k.reloaded) {
+		return k.keys.Load().checkSignable(NowFunc())
+	}
+	keys, err := LoadKeys(path)
+	if err != nil {
+		return err
+	}
+	if err := k.setKeys(keys); err != nil {
+		return err
+	}
+	// If the files changed since they were hashed, the next Reload sees a
+	// different hash and loads them again.
+	k.reloaded = sum
+	return nil
+}
+
+// keysSum returns a hash of the path and the contents of the files LoadKeys
+// reads from it.
+func keysSum(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), ".") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:%s", len(path), path)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(h, "%d:%s%d:", len(file), file, len(data))
+		h.Write(data)
+	}
+	return h.Sum(nil), nil
+}
+
+// Poll calls Reload with the given path every interval until ctx is done.
+// Errors are passed to onError, if not nil, and polling continues with the
+// current keys. Poll blocks, so is usually run in its own goroutine.
+func (k *Keyring) Poll(ctx context.Context, path string, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Reload(path); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,597 @@
+package itsdangerous_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func newKeyringSigner(t *testing.T, keys ...itsdangerous.Key) *itsdangerous.BackendSigner {
+	t.Helper()
+	keyring, err := itsdangerous.NewKeyring(keys, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	sig, err := itsdangerous.NewSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithKeyring returned error: %s", err)
+	}
+	return sig
+}
+
+func TestKeyringKeyID(t *testing.T) {
+	old := itsdangerous.Key{ID: "old", Secret: "secret_key"}
+	current := itsdangerous.Key{ID: "current", Secret: "new_secret_key"}
+
+	tests := []struct {
+		name   string
+		signed string
+		keyID  string
+	}{
+		{name: "current key", signed: signContext(t, newKeyringSigner(t, current), "my string"), keyID: "current"},
+		{name: "old key", signed: signContext(t, newKeyringSigner(t, old), "my string"), keyID: "old"},
+		{name: "legacy signature", signed: "my string.xv0r21ogoygusbkJA01c4OxsAio", keyID: "old"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			sig := newKeyringSigner(t, old, current)
+
+			actual, keyID, err := sig.UnsignWithKeyID(context.Background(), test.signed)
+			if err != nil {
+				t.Fatalf("UnsignWithKeyID(%s) returned error: %s", test.signed, err)
+			}
+			if actual != "my string" {
+				t.Errorf("UnsignWithKeyID(%s) got %s; want %s", test.signed, actual, "my string")
+			}
+			if keyID != test.keyID {
+				t.Errorf("UnsignWithKeyID(%s) got key ID %s; want %s", test.signed, keyID, test.keyID)
+			}
+		})
+	}
+}
+
+func TestKeyringSignsWithNewestKey(t *testing.T) {
+	sig := newKeyringSigner(t,
+		itsdangerous.Key{ID: "old", Secret: "secret_key"},
+		itsdangerous.Key{ID: "current", Secret: "new_secret_key"})
+
+	signed := signContext(t, sig, "my string")
+	if _, err := newKeyringSigner(t, itsdangerous.Key{ID: "current", Secret: "new_secret_key"}).UnsignContext(context.Background(), signed); err != nil {
+		t.Errorf("Unsign(%s) with newest key returned error: %s", signed, err)
+	}
+}
+
+func TestKeyringUnknownKey(t *testing.T) {
+	signed := signContext(t, newKeyringSigner(t, itsdangerous.Key{ID: "gone", Secret: "secret_key"}), "my string")
+	sig := newKeyringSigner(t, itsdangerous.Key{ID: "current", Secret: "new_secret_key"})
+
+	_, err := sig.UnsignContext(context.Background(), signed)
+	if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+		t.Fatalf("Unsign(%s) expected InvalidSignatureError; got %v", signed, err)
+	}
+	var unknownErr itsdangerous.UnknownKeyError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("Unsign(%s) expected UnknownKeyError; got %T(%s)", signed, err, err.Error())
+	}
+	if unknownErr.KeyID() != "gone" {
+		t.Errorf("UnknownKeyError.KeyID() got %s; want gone", unknownErr.KeyID())
+	}
+}
+
+func TestKeyringInvalidSignature(t *testing.T) {
+	sig := newKeyringSigner(t, itsdangerous.Key{ID: "current", Secret: "new_secret_key"})
+	signed := signContext(t, sig, "my string")
+
+	for _, input := range []string{
+		"my other string" + signed[len("my string"):],
+		"my string.xv0r21ogoygusbkJA01c4OxsAio",
+		"my string",
+	} {
+		_, err := sig.UnsignContext(context.Background(), input)
+		if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+			t.Errorf("Unsign(%s) expected InvalidSignatureError; got %v", input, err)
+		}
+		if errors.As(err, &itsdangerous.UnknownKeyError{}) {
+			t.Errorf("Unsign(%s) expected signature mismatch; got %v", input, err)
+		}
+	}
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []itsdangerous.Key
+	}{
+		{name: "no keys"},
+		{name: "empty ID", keys: []itsdangerous.Key{{Secret: "secret_key"}}},
+		{name: "invalid ID", keys: []itsdangerous.Key{{ID: "key 1", Secret: "secret_key"}}},
+		{name: "duplicate ID", keys: []itsdangerous.Key{{ID: "key", Secret: "a"}, {ID: "key", Secret: "b"}}},
+		{name: "empty window", keys: []itsdangerous.Key{{ID: "key", Secret: "a",
+			NotBefore: time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC), NotAfter: time.Date(2024, 9, 27, 13, 0, 0, 0, time.UTC)}}},
+		{name: "verify-only", keys: []itsdangerous.Key{{ID: "key", Secret: "a", Status: itsdangerous.KeyVerifyOnly}}},
+		{name: "retired", keys: []itsdangerous.Key{{ID: "key", Secret: "a", Status: itsdangerous.KeyRetired}}},
+		{name: "expired", keys: []itsdangerous.Key{{ID: "key", Secret: "a", NotAfter: time.Date(2024, 9, 27, 13, 0, 0, 0, time.UTC)}}},
+		{name: "retired before valid", keys: []itsdangerous.Key{{ID: "key", Secret: "a",
+			NotBefore: time.Date(2024, 9, 27, 15, 0, 0, 0, time.UTC), RetireAt: time.Date(2024, 9, 27, 15, 0, 0, 0, time.UTC)}}},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC) }
+			defer func() { itsdangerous.NowFunc = time.Now }()
+
+			if _, err := itsdangerous.NewKeyring(test.keys, "salt"); err == nil {
+				t.Errorf("NewKeyring(%v) expected error", test.keys)
+			}
+		})
+	}
+}
+
+func TestTimestampSignerKeyring(t *testing.T) {
+	keyring, err := itsdangerous.NewKeyring([]itsdangerous.Key{{ID: "current", Secret: "secret_key"}}, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	sig, err := itsdangerous.NewTimestampSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewTimestampSignerWithKeyring returned error: %s", err)
+	}
+
+	signed := signContext(t, sig, "my string")
+	actual, keyID, err := sig.UnsignWithKeyID(context.Background(), signed, 0)
+	if err != nil {
+		t.Fatalf("UnsignWithKeyID(%s) returned error: %s", signed, err)
+	}
+	if actual != "my string" || keyID != "current" {
+		t.Errorf("UnsignWithKeyID(%s) got %s, %s; want my string, current", signed, actual, keyID)
+	}
+}
+
+func TestKeyringSetKeys(t *testing.T) {
+	keyring, err := itsdangerous.NewKeyring([]itsdangerous.Key{{ID: "old", Secret: "secret_key"}}, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	s := itsdangerous.NewURLSafeTimedSerializerWithKeyring(keyring)
+	oldToken, err := s.MarshalContext(context.Background(), "my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+
+	if err := keyring.SetKeys([]itsdangerous.Key{{ID: "current", Secret: "new_secret_key"}}); err != nil {
+		t.Fatalf("SetKeys returned error: %s", err)
+	}
+	var actual string
+	if err := s.UnmarshalContext(context.Background(), oldToken, &actual, 0); !errors.As(err, &itsdangerous.UnknownKeyError{}) {
+		t.Errorf("Unmarshal(%s) after SetKeys expected UnknownKeyError; got %v", oldToken, err)
+	}
+	newToken, err := s.MarshalContext(context.Background(), "my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	sig, err := itsdangerous.NewTimestampSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewTimestampSignerWithKeyring returned error: %s", err)
+	}
+	if _, keyID, err := sig.UnsignWithKeyID(context.Background(), newToken, 0); err != nil || keyID != "current" {
+		t.Errorf("UnsignWithKeyID(%s) got key ID %s, %v; want current", newToken, keyID, err)
+	}
+
+	if err := keyring.SetKeys(nil); err == nil {
+		t.Errorf("SetKeys(nil) expected error")
+	}
+	if err := s.UnmarshalContext(context.Background(), newToken, &actual, 0); err != nil {
+		t.Errorf("Unmarshal(%s) after failed SetKeys returned error: %s", newToken, err)
+	}
+}
+
+func TestKeyringConcurrentSetKeys(t *testing.T) {
+	keys := [][]itsdangerous.Key{
+		{{ID: "a", Secret: "secret_a"}},
+		{{ID: "a", Secret: "secret_a"}, {ID: "b", Secret: "secret_b"}},
+	}
+	keyring, err := itsdangerous.NewKeyring(keys[0], "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	sig, err := itsdangerous.NewSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithKeyring returned error: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				signed, err := sig.SignContext(context.Background(), "my string")
+				if err != nil {
+					t.Errorf("SignContext returned error: %s", err)
+					return
+				}
+				// Key a is always present, but b may have been removed
+				// since signing.
+				if _, err := sig.UnsignContext(context.Background(), signed); err != nil && !errors.As(err, &itsdangerous.UnknownKeyError{}) {
+					t.Errorf("Unsign(%s) returned error: %s", signed, err)
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		if err := keyring.SetKeys(keys[j%2]); err != nil {
+			t.Fatalf("SetKeys returned error: %s", err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "keys")
+	if err := os.WriteFile(file, []byte("# keys\n2023-01 secret_key\n\n2024-01 new_secret_key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keysDir := filepath.Join(dir, "keys.d")
+	if err := os.Mkdir(keysDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"2024-01": "new_secret_key\n",
+		"2023-01": "secret_key",
+		".hidden": "ignored",
+	} {
+		if err := os.WriteFile(filepath.Join(keysDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []itsdangerous.Key{
+		{ID: "2023-01", Secret: "secret_key"},
+		{ID: "2024-01", Secret: "new_secret_key"},
+	}
+	for _, path := range []string{file, keysDir} {
+		keys, err := itsdangerous.LoadKeys(path)
+		if err != nil {
+			t.Fatalf("LoadKeys(%s) returned error: %s", path, err)
+		}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("LoadKeys(%s) got %v; want %v", path, keys, expected)
+		}
+	}
+
+	if err := os.WriteFile(file, []byte("2023-01\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := itsdangerous.LoadKeys(file); err == nil {
+		t.Errorf("LoadKeys(%s) expected error for line without secret", file)
+	}
+}
+
+func TestKeyringPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("old secret_key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := itsdangerous.LoadKeys(path)
+	if err != nil {
+		t.Fatalf("LoadKeys returned error: %s", err)
+	}
+	keyring, err := itsdangerous.NewKeyring(keys, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		keyring.Poll(ctx, path, time.Millisecond, nil)
+	}()
+	// Wait for Poll to stop so it doesn't call NowFunc during other tests.
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if err := os.WriteFile(path, []byte("old secret_key\ncurrent new_secret_key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(keyring.Keys()) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Poll did not reload keys; got %v", keyring.Keys())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newKeyringTimestampSigner(t *testing.T, keys ...itsdangerous.Key) *itsdangerous.BackendTimestampSigner {
+	t.Helper()
+	keyring, err := itsdangerous.NewKeyring(keys, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	sig, err := itsdangerous.NewTimestampSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewTimestampSignerWithKeyring returned error: %s", err)
+	}
+	return sig
+}
+
+func TestKeyringSignsWithActiveKey(t *testing.T) {
+	now := time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)
+	itsdangerous.NowFunc = func() time.Time { return now }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	sig := newKeyringTimestampSigner(t,
+		itsdangerous.Key{ID: "old", Secret: "secret_key", NotAfter: now.Add(time.Hour)},
+		itsdangerous.Key{ID: "current", Secret: "new_secret_key", NotBefore: now.Add(time.Minute)})
+
+	tests := []struct {
+		now   time.Time
+		keyID string
+	}{
+		{now: now, keyID: "old"},
+		{now: now.Add(time.Minute), keyID: "current"},
+		{now: now.Add(2 * time.Hour), keyID: "current"},
+	}
+	for _, test := range tests {
+		itsdangerous.NowFunc = func() time.Time { return test.now }
+		signed := signContext(t, sig, "my string")
+		if _, keyID, err := sig.UnsignWithKeyID(context.Background(), signed, 0); err != nil || keyID != test.keyID {
+			t.Errorf("UnsignWithKeyID(%s) at %s got key ID %s, %v; want %s", signed, test.now, keyID, err, test.keyID)
+		}
+	}
+
+	itsdangerous.NowFunc = func() time.Time { return now }
+	sig = newKeyringTimestampSigner(t, itsdangerous.Key{ID: "future", Secret: "secret_key", NotBefore: now.Add(time.Hour)})
+	if _, err := sig.SignContext(context.Background(), "my string"); err == nil {
+		t.Errorf("SignContext expected error with no active key")
+	}
+
+	// Once every key has expired, signing returns an error from each of the
+	// methods taking a context.
+	sig = newKeyringTimestampSigner(t, itsdangerous.Key{ID: "old", Secret: "secret_key", NotAfter: now.Add(time.Hour)})
+	itsdangerous.NowFunc = func() time.Time { return now.Add(2 * time.Hour) }
+	ctx := context.Background()
+	for name, sign := range map[string]func() (string, error){
+		"SignContext":              func() (string, error) { return sig.SignContext(ctx, "my string") },
+		"SignDetachedContext":      func() (string, error) { return sig.SignDetachedContext(ctx, "my string") },
+		"SignWithExpiryContext":    func() (string, error) { return sig.SignWithExpiryContext(ctx, "my string", time.Hour) },
+		"SignWithNotBeforeContext": func() (string, error) { return sig.SignWithNotBeforeContext(ctx, "my string", now, 0) },
+	} {
+		if _, err := sign(); err == nil {
+			t.Errorf("%s expected error with no active key", name)
+		}
+	}
+}
+
+func TestKeyringValidityWindow(t *testing.T) {
+	start := time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	sign := func(at time.Time) string {
+		itsdangerous.NowFunc = func() time.Time { return at }
+		return signContext(t, newKeyringTimestampSigner(t, itsdangerous.Key{ID: "old", Secret: "secret_key"}), "my string")
+	}
+	tests := []struct {
+		name        string
+		signed      string
+		expectValid bool
+	}{
+		{name: "before window", signed: sign(start.Add(-time.Second))},
+		{name: "start of window", signed: sign(start), expectValid: true},
+		{name: "end of window", signed: sign(start.Add(time.Hour)), expectValid: true},
+		{name: "after window", signed: sign(start.Add(time.Hour + time.Second))},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			itsdangerous.NowFunc = func() time.Time { return start.Add(2 * time.Hour) }
+			sig := newKeyringTimestampSigner(t,
+				itsdangerous.Key{ID: "old", Secret: "secret_key", NotBefore: start, NotAfter: start.Add(time.Hour)},
+				itsdangerous.Key{ID: "current", Secret: "new_secret_key", NotBefore: start.Add(time.Hour)})
+
+			_, err := sig.UnsignContext(context.Background(), test.signed, 0)
+			if test.expectValid {
+				if err != nil {
+					t.Errorf("Unsign(%s) returned error: %s", test.signed, err)
+				}
+			} else if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+				t.Errorf("Unsign(%s) expected InvalidSignatureError; got %v", test.signed, err)
+			}
+		})
+	}
+}
+
+func TestKeyringRetiredKey(t *testing.T) {
+	now := time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)
+	itsdangerous.NowFunc = func() time.Time { return now }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	old := itsdangerous.Key{ID: "old", Secret: "secret_key", RetireAt: now.Add(time.Hour)}
+	current := itsdangerous.Key{ID: "current", Secret: "new_secret_key"}
+	tests := []struct {
+		name   string
+		signed string
+	}{
+		{name: "key ID", signed: signContext(t, newKeyringSigner(t, old), "my string")},
+		{name: "legacy signature", signed: "my string.xv0r21ogoygusbkJA01c4OxsAio"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			itsdangerous.NowFunc = func() time.Time { return now }
+			sig := newKeyringSigner(t, old, current)
+			if _, err := sig.UnsignContext(context.Background(), test.signed); err != nil {
+				t.Fatalf("Unsign(%s) before retirement returned error: %s", test.signed, err)
+			}
+
+			itsdangerous.NowFunc = func() time.Time { return now.Add(time.Hour) }
+			_, err := sig.UnsignContext(context.Background(), test.signed)
+			if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+				t.Fatalf("Unsign(%s) expected InvalidSignatureError; got %v", test.signed, err)
+			}
+			if !errors.As(err, &itsdangerous.RetiredKeyError{}) {
+				t.Errorf("Unsign(%s) expected RetiredKeyError; got %T(%s)", test.signed, err, err.Error())
+			}
+		})
+	}
+}
+
+func TestKeyringFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	f := &itsdangerous.KeyringFile{
+		Version: itsdangerous.KeyringFileVersion,
+		Keys: []itsdangerous.KeyringFileKey{
+			{ID: "old", Status: itsdangerous.KeyRetired, Secret: "old_secret_key"},
+			{ID: "legacy", Status: itsdangerous.KeyVerifyOnly, Secret: "secret_key"},
+			{ID: "current", Status: itsdangerous.KeyActive, Secret: "new_secret_key", Derivation: "hmac", Digest: "sha256",
+				Created: time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)},
+		},
+	}
+	if err := f.Write(path); err != nil {
+		t.Fatalf("Write returned error: %s", err)
+	}
+	// Unset times are omitted.
+	if data, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(string(data), "0001-01-01") || strings.Count(string(data), `"created": "2024-09-27T14:00:00Z"`) != 1 {
+		t.Errorf("Write wrote %s", data)
+	}
+	if read, err := itsdangerous.ReadKeyringFile(path); err != nil || !read.Keys[2].Created.Equal(f.Keys[2].Created) || !read.Keys[0].Created.IsZero() {
+		t.Errorf("ReadKeyringFile got unexpected created times or error %v", err)
+	}
+
+	keyring, err := itsdangerous.LoadKeyringFile(path, "salt")
+	if err != nil {
+		t.Fatalf("LoadKeyringFile returned error: %s", err)
+	}
+	sig, err := itsdangerous.NewSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewSignerWithKeyring returned error: %s", err)
+	}
+
+	// The legacy key is verify-only, so still accepts tokens it signed.
+	if _, keyID, err := sig.UnsignWithKeyID(context.Background(), "my string.xv0r21ogoygusbkJA01c4OxsAio"); err != nil || keyID != "legacy" {
+		t.Errorf("UnsignWithKeyID got key ID %s, %v; want legacy", keyID, err)
+	}
+	signed := signContext(t, sig, "my string")
+	if _, keyID, err := sig.UnsignWithKeyID(context.Background(), signed); err != nil || keyID != "current" {
+		t.Errorf("UnsignWithKeyID(%s) got key ID %s, %v; want current", signed, keyID, err)
+	}
+	// An HMAC-SHA256 signature is 32 bytes, plus the key ID and its length,
+	// which encodes as 54 base64 characters.
+	if l := len(signed) - len("my string."); l != 54 {
+		t.Errorf("Sign got signature of length %d; want 54", l)
+	}
+	signed = signContext(t, newKeyringSigner(t, itsdangerous.Key{ID: "old", Secret: "old_secret_key"}), "my string")
+	if _, err := sig.UnsignContext(context.Background(), signed); !errors.As(err, &itsdangerous.RetiredKeyError{}) {
+		t.Errorf("Unsign(%s) expected RetiredKeyError; got %v", signed, err)
+	}
+
+	keys, err := itsdangerous.LoadKeys(path)
+	if err != nil {
+		t.Fatalf("LoadKeys(%s) returned error: %s", path, err)
+	}
+	if len(keys) != 3 || keys[2].ID != "current" || keys[2].Digest == nil {
+		t.Errorf("LoadKeys(%s) got %v", path, keys)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 2, "keys": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := itsdangerous.ReadKeyringFile(path); err == nil {
+		t.Errorf("ReadKeyringFile expected error for unsupported version")
+	}
+}
+
+func TestKeyringStats(t *testing.T) {
+	now := time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)
+	itsdangerous.NowFunc = func() time.Time { return now }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	keyring, err := itsdangerous.NewKeyring([]itsdangerous.Key{
+		{ID: "old", Secret: "secret_key"},
+		{ID: "current", Secret: "new_secret_key"},
+	}, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	s := itsdangerous.NewURLSafeTimedSerializerWithKeyring(keyring)
+
+	signed, err := s.MarshalContext(context.Background(), "my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	var actual string
+	for i := 0; i < 3; i++ {
+		if err := s.UnmarshalContext(context.Background(), signed, &actual, 0); err != nil {
+			t.Fatalf("Unmarshal(%s) returned error: %s", signed, err)
+		}
+	}
+	bound, err := s.MarshalBoundContext(context.Background(), "my string", "binding")
+	if err != nil {
+		t.Fatalf("MarshalBound returned error: %s", err)
+	}
+	if err := s.UnmarshalBoundContext(context.Background(), bound, &actual, 0, "binding"); err != nil {
+		t.Fatalf("UnmarshalBound(%s) returned error: %s", bound, err)
+	}
+	if err := s.UnmarshalContext(context.Background(), signed+"x", &actual, 0); err == nil {
+		t.Fatalf("Unmarshal(%s) expected error", signed+"x")
+	}
+
+	// Stats are kept when the keys change.
+	if err := keyring.SetKeys(keyring.Keys()); err != nil {
+		t.Fatalf("SetKeys returned error: %s", err)
+	}
+	expected := map[string]itsdangerous.KeyStats{
+		"old":     {},
+		"current": {Verified: 4, LastUsed: now},
+	}
+	stats := keyring.Stats()
+	if len(stats) != len(expected) {
+		t.Fatalf("Stats() got %v; want %v", stats, expected)
+	}
+	for id, st := range expected {
+		if stats[id].Verified != st.Verified || !stats[id].LastUsed.Equal(st.LastUsed) {
+			t.Errorf("Stats()[%s] got %+v; want %+v", id, stats[id], st)
+		}
+	}
+
+	var decoded map[string]itsdangerous.KeyStats
+	if err := json.Unmarshal([]byte(keyring.StatsVar().String()), &decoded); err != nil {
+		t.Fatalf("StatsVar().String() is not JSON: %s", err)
+	}
+	if decoded["current"].Verified != 4 {
+		t.Errorf("StatsVar().String() got %v; want current verified 4", decoded)
+	}
+
+	// expvar names are global, so use a new one each time the test runs.
+	statsVarRuns++
+	name := fmt.Sprintf("itsdangerous_test_keyring_%d", statsVarRuns)
+	expvar.Publish(name, keyring.StatsVar())
+	decoded = nil
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &decoded); err != nil {
+		t.Fatalf("published StatsVar is not JSON: %s", err)
+	}
+	if decoded["current"].Verified != 4 {
+		t.Errorf("published StatsVar got %v; want current verified 4", decoded)
+	}
+}
+
+// statsVarRuns counts the runs of TestKeyringStats.
+var statsVarRuns int
@@ -0,0 +1,176 @@
+package itsdangerous
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"hash"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReplayStore records the IDs of one-time tokens that have already been used.
+type ReplayStore interface {
+	// Use marks the token ID as used until expires and reports whether it
+	// was unused before. A zero expires means the ID must be remembered
+	// forever. Implementations must be safe for concurrent use.
+	Use(id string, expires time.Time) (bool, error)
+}
+
+// MemoryReplayStore is an in-memory ReplayStore. IDs are evicted once their
+// tokens would have expired anyway.
+type MemoryReplayStore struct {
+	mu        sync.Mutex
+	used      map[string]time.Time
+	sweepSize int
+}
+
+// NewMemoryReplayStore creates a new empty MemoryReplayStore.
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{used: make(map[string]time.Time)}
+}
+
+// Use marks the token ID as used until expires.
+func (m *MemoryReplayStore) Use(id string, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := NowFunc()
+	// Sweep expired IDs whenever the store has doubled in size since the
+	// last sweep, so eviction is amortised across calls.
+	if len(m.used) >= 2*m.sweepSize {
+		for k, exp := range m.used {
+			if !exp.IsZero() && !exp.After(now) {
+				delete(m.used, k)
+			}
+		}
+		m.sweepSize = len(m.used)
+	}
+
+	if exp, ok := m.used[id]; ok && (exp.IsZero() || exp.After(now)) {
+		return false, nil
+	}
+	m.used[id] = expires
+	return true, nil
+}
+
+// OneTimeSerializer works like URLSafeTimedSerializer but every token carries
+// a random ID which is recorded in a ReplayStore when the token is
+// unmarshalled, so each token can only be used once. It only has the methods
+// which go through the ReplayStore; the serializer it signs with is not
+// exposed, as its methods would make tokens which could be replayed.
+type OneTimeSerializer struct {
+	serializer URLSafeTimedSerializer
+
+	Store ReplayStore
+}
+
+// NewOneTimeSerializer creates a new OneTimeSerializer with the given secret,
+// salt and replay store.
+func NewOneTimeSerializer(secret, salt string, store ReplayStore) *OneTimeSerializer {
+	return NewOneTimeSerializerFromSerializer(NewURLSafeTimedSerializer(secret, salt), store)
+}
+
+// NewParentOneTimeSerializer creates a new OneTimeSerializer with the given
+// replay store which keeps the secret, so that WithSalt can create children
+// sharing the store. See NewParentSigner.
+func NewParentOneTimeSerializer(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm, store ReplayStore) (*OneTimeSerializer, error) {
+	s, err := NewParentURLSafeTimedSerializer(secret, salt, sep, derivation, digest, algo)
+	if err != nil {
+		return nil, err
+	}
+	return NewOneTimeSerializerFromSerializer(s, store), nil
+}
+
+// NewOneTimeSerializerFromSerializer creates a new OneTimeSerializer which
+// signs like the given serializer, keeping its Purpose, MillisecondTimestamps
+// and RevocationChecker, and records tokens in the given replay store. Tokens
+// of the two serializers are not interchangeable.
+func NewOneTimeSerializerFromSerializer(s *URLSafeTimedSerializer, store ReplayStore) *OneTimeSerializer {
+	return &OneTimeSerializer{serializer: *s, Store: store}
+}
+
+// Marshal signs the value as a token with a new random ID.
+func (s *OneTimeSerializer) Marshal(value interface{}) (string, error) {
+	return s.MarshalContext(context.Background(), value)
+}
+
+// MarshalContext works like Marshal but passes ctx to the signing backend.
+func (s *OneTimeSerializer) MarshalContext(ctx context.Context, value interface{}) (string, error) {
+	encoded, err := s.serializer.encode(value)
+	if err != nil {
+		return "", err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	signer, err := s.signer()
+	if err != nil {
+		return "", err
+	}
+	return signer.SignContext(ctx, encoded+s.serializer.sep+base64Encode(id))
+}
+
+// Unmarshal verifies the token and marks it as used. Tokens which have
+// already been used are rejected with a TokenReusedError.
+func (s *OneTimeSerializer) Unmarshal(signed string, value interface{}, maxAge time.Duration) error {
+	return s.UnmarshalContext(context.Background(), signed, value, maxAge)
+}
+
+// UnmarshalContext works like Unmarshal but passes ctx to the signing
+// backend.
+func (s *OneTimeSerializer) UnmarshalContext(ctx context.Context, signed string, value interface{}, maxAge time.Duration) error {
+	signer, err := s.signer()
+	if err != nil {
+		return err
+	}
+	result, issued, _, err := signer.unsign(ctx, signed, maxAge)
+	if err != nil {
+		return err
+	}
+
+	li := strings.LastIndex(result, s.serializer.sep)
+	if li <= 0 {
+		return InvalidSignatureError{errors.New("token ID missing")}
+	}
+	encoded, id := result[:li], result[li+len(s.serializer.sep):]
+
+	encoded, err = s.serializer.checkPurpose(encoded)
+	if err != nil {
+		return err
+	}
+
+	var expires time.Time
+	if maxAge > 0 {
+		expires = issued.Add(maxAge)
+	}
+	ok, err := s.Store.Use(id, expires)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return tokenReused(id)
+	}
+
+	return urlSafeDeserialize(encoded, value)
+}
+
+// signer returns the signer for one-time tokens. Its key is derived from the
+// serializer's, so that other tokens signed with the same key, such as those
+// of a URLSafeTimedSerializer with a Purpose, can't be taken for one-time
+// tokens.
+func (s *OneTimeSerializer) signer() (*TimestampSigner, error) {
+	b, ok := s.serializer.backend.(binder)
+	if !ok {
+		return nil, errors.New("signing backend does not support one-time tokens")
+	}
+	signer := s.serializer.TimestampSigner
+	// Signer.bound prefixes its data with "bind", so this can't collide
+	// with tokens from MarshalBound.
+	signer.backend = b.bind("one-time")
+	return &signer, nil
+}
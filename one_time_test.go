@@ -0,0 +1,129 @@
+package itsdangerous_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestOneTimeSerializer(t *testing.T) {
+	now := time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)
+	itsdangerous.NowFunc = func() time.Time { return now }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	sig := itsdangerous.NewOneTimeSerializer("secret_key", "salt", itsdangerous.NewMemoryReplayStore())
+
+	first, err := sig.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	second, err := sig.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	if first == second {
+		t.Fatalf("Marshal returned the same token twice: %s", first)
+	}
+
+	var actual interface{}
+	if err := sig.Unmarshal(first, &actual, 5*time.Minute); err != nil {
+		t.Fatalf("Unmarshal(%s) returned error: %s", first, err)
+	}
+	if actual != "my string" {
+		t.Errorf("Unmarshal(%s) got %#v; want %#v", first, actual, "my string")
+	}
+
+	err = sig.Unmarshal(first, &actual, 5*time.Minute)
+	if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+		t.Fatalf("Unmarshal(%s) expected InvalidSignatureError; got %v", first, err)
+	}
+	if !errors.As(err, &itsdangerous.TokenReusedError{}) {
+		t.Fatalf("Unmarshal(%s) expected TokenReusedError; got %T(%s)", first, err, err.Error())
+	}
+
+	// Other tokens are unaffected
+	if err := sig.Unmarshal(second, &actual, 5*time.Minute); err != nil {
+		t.Fatalf("Unmarshal(%s) returned error: %s", second, err)
+	}
+
+	// A plain timed token has no ID so is rejected
+	plain, err := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt").Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	if err := sig.Unmarshal(plain, &actual, 5*time.Minute); err == nil {
+		t.Fatalf("Unmarshal(%s) expected error; got no error", plain)
+	}
+
+	// Nor is one with a purpose, though the purpose is where the ID would be
+	timed := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+	timed.Purpose = "reset"
+	plain, err = timed.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	if err := sig.Unmarshal(plain, &actual, 5*time.Minute); err == nil {
+		t.Fatalf("Unmarshal(%s) expected error; got no error", plain)
+	}
+}
+
+func TestOneTimeSerializerFromSerializer(t *testing.T) {
+	store := itsdangerous.NewMemoryReplayStore()
+	timed := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+	timed.Purpose = "reset"
+	sig := itsdangerous.NewOneTimeSerializerFromSerializer(timed, store)
+
+	signed, err := sig.Marshal("my string")
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+	login := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+	login.Purpose = "login"
+	var actual interface{}
+	err = itsdangerous.NewOneTimeSerializerFromSerializer(login, store).Unmarshal(signed, &actual, 0)
+	if !errors.As(err, &itsdangerous.WrongPurposeError{}) {
+		t.Errorf("Unmarshal(%s) expected WrongPurposeError; got %v", signed, err)
+	}
+	if err := sig.Unmarshal(signed, &actual, 0); err != nil {
+		t.Errorf("Unmarshal(%s) returned error: %s", signed, err)
+	}
+
+	// Tokens of the wrapped serializer are not one-time tokens
+	plain, err := timed.MarshalWithExpiry("my string", time.Hour)
+	if err != nil {
+		t.Fatalf("MarshalWithExpiry returned error: %s", err)
+	}
+	if err := sig.Unmarshal(plain, &actual, 0); err == nil {
+		t.Errorf("Unmarshal(%s) expected error; got no error", plain)
+	}
+}
+
+func TestMemoryReplayStore(t *testing.T) {
+	now := time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)
+	itsdangerous.NowFunc = func() time.Time { return now }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	store := itsdangerous.NewMemoryReplayStore()
+
+	use := func(id string, expires time.Time, want bool) {
+		t.Helper()
+		ok, err := store.Use(id, expires)
+		if err != nil {
+			t.Fatalf("Use(%s) returned error: %s", id, err)
+		}
+		if ok != want {
+			t.Errorf("Use(%s) got %t; want %t", id, ok, want)
+		}
+	}
+
+	use("a", now.Add(time.Minute), true)
+	use("a", now.Add(time.Minute), false)
+	use("forever", time.Time{}, true)
+
+	now = now.Add(2 * time.Minute)
+	// "a" has expired so may be used again
+	use("a", now.Add(time.Minute), true)
+	use("forever", time.Time{}, false)
+}
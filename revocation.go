@@ -0,0 +1,101 @@
+package itsdangerous
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+)
+
+// RevocationChecker reports whether a token has been revoked. Tokens are
+// identified by their signature, ie the part of the signed string after the
+// final separator.
+type RevocationChecker interface {
+	IsRevoked(id string) (bool, error)
+}
+
+// RevocationSet is an in-memory RevocationChecker. It is safe for concurrent
+// use.
+type RevocationSet struct {
+	mu  sync.RWMutex
+	ids map[string]struct{}
+}
+
+// NewRevocationSet creates a new RevocationSet containing the given IDs.
+func NewRevocationSet(ids ...string) *RevocationSet {
+	r := &RevocationSet{ids: make(map[string]struct{}, len(ids))}
+	for _, id := range ids {
+		r.ids[id] = struct{}{}
+	}
+	return r
+}
+
+// Revoke adds the given ID to the set.
+func (r *RevocationSet) Revoke(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids[id] = struct{}{}
+}
+
+// IsRevoked reports whether the given ID is in the set.
+func (r *RevocationSet) IsRevoked(id string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.ids[id]
+	return ok, nil
+}
+
+// FileRevocationList is a RevocationChecker backed by a file containing one
+// revoked ID per line. Blank lines and lines starting with # are ignored.
+// The file is read on creation and again on each call to Reload.
+type FileRevocationList struct {
+	path string
+	mu   sync.RWMutex
+	set  *RevocationSet
+}
+
+// NewFileRevocationList creates a new FileRevocationList and loads the given
+// file.
+func NewFileRevocationList(path string) (*FileRevocationList, error) {
+	l := &FileRevocationList{path: path}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Reload re-reads the file, replacing the current set of revoked IDs. If the
+// file can't be read the current set is kept.
+func (l *FileRevocationList) Reload() error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.set = NewRevocationSet(ids...)
+	return nil
+}
+
+// IsRevoked reports whether the given ID was listed in the file when it was
+// last loaded.
+func (l *FileRevocationList) IsRevoked(id string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.set.IsRevoked(id)
+}
@@ -0,0 +1,112 @@
+package itsdangerous_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestSignerRevocation(t *testing.T) {
+	tests := []struct {
+		input         string
+		revoked       []string
+		expectRevoked bool
+	}{
+		{input: "my string.xv0r21ogoygusbkJA01c4OxsAio"},
+		{input: "my string.xv0r21ogoygusbkJA01c4OxsAio", revoked: []string{"xv0r21ogoygusbkJA01c4OxsAio"},
+			expectRevoked: true},
+		{input: "my string.xv0r21ogoygusbkJA01c4OxsAio", revoked: []string{"Ot23yopX-I7Y6_e0hoZg6VKAcLk"}},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.input, func(t *testing.T) {
+			sig := itsdangerous.NewSigner("secret_key", "salt")
+			sig.RevocationChecker = itsdangerous.NewRevocationSet(test.revoked...)
+
+			actual, err := sig.Unsign(test.input)
+			if test.expectRevoked {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("Unsign(%s) expected InvalidSignatureError; got %v", test.input, err)
+				}
+				if !errors.As(err, &itsdangerous.RevokedError{}) {
+					t.Fatalf("Unsign(%s) expected RevokedError; got %T(%s)", test.input, err, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unsign(%s) returned error: %s", test.input, err)
+				}
+				if actual != "my string" {
+					t.Errorf("Unsign(%s) got %s; want %s", test.input, actual, "my string")
+				}
+			}
+		})
+	}
+}
+
+func TestURLSafeSerializerRevocation(t *testing.T) {
+	revoked := itsdangerous.NewRevocationSet()
+	sig := itsdangerous.NewURLSafeSerializer("secret_key", "salt")
+	sig.RevocationChecker = revoked
+
+	var actual interface{}
+	if err := sig.Unmarshal("Im15IHN0cmluZyI.Cm-9vjbVa2uq2UcarUKVT4ETsJM", &actual); err != nil {
+		t.Fatalf("Unmarshal returned error: %s", err)
+	}
+
+	revoked.Revoke("Cm-9vjbVa2uq2UcarUKVT4ETsJM")
+	err := sig.Unmarshal("Im15IHN0cmluZyI.Cm-9vjbVa2uq2UcarUKVT4ETsJM", &actual)
+	if !errors.As(err, &itsdangerous.RevokedError{}) {
+		t.Fatalf("Unmarshal expected RevokedError; got %v", err)
+	}
+}
+
+func TestFileRevocationList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revoked.txt")
+	if err := os.WriteFile(path, []byte("# leaked links\n\nCm-9vjbVa2uq2UcarUKVT4ETsJM\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := itsdangerous.NewFileRevocationList(path)
+	if err != nil {
+		t.Fatalf("NewFileRevocationList returned error: %s", err)
+	}
+
+	isRevoked := func(id string, want bool) {
+		t.Helper()
+		revoked, err := list.IsRevoked(id)
+		if err != nil {
+			t.Fatalf("IsRevoked(%s) returned error: %s", id, err)
+		}
+		if revoked != want {
+			t.Errorf("IsRevoked(%s) got %t; want %t", id, revoked, want)
+		}
+	}
+	isRevoked("Cm-9vjbVa2uq2UcarUKVT4ETsJM", true)
+	isRevoked("# leaked links", false)
+	isRevoked("xv0r21ogoygusbkJA01c4OxsAio", false)
+
+	if err := os.WriteFile(path, []byte("xv0r21ogoygusbkJA01c4OxsAio\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Reload(); err != nil {
+		t.Fatalf("Reload returned error: %s", err)
+	}
+	isRevoked("Cm-9vjbVa2uq2UcarUKVT4ETsJM", false)
+	isRevoked("xv0r21ogoygusbkJA01c4OxsAio", true)
+
+	// A failed reload keeps the current list
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Reload(); err == nil {
+		t.Errorf("Reload expected error for missing file; got no error")
+	}
+	isRevoked("xv0r21ogoygusbkJA01c4OxsAio", true)
+
+	if _, err := itsdangerous.NewFileRevocationList(path); err == nil {
+		t.Errorf("NewFileRevocationList expected error for missing file; got no error")
+	}
+}
@@ -0,0 +1,272 @@
+package itsdangerous
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"os"
+	"strings"
+)
+
+// GenerateSecret returns a new random secret with 384 bits of entropy,
+// encoded as URL-safe base64 so it can be stored as text. This is more than
+// any digest needs, so that CheckSecretStrength accepts it with any digest
+// even if it happens to use only hexadecimal digits. It panics if the
+// system's secure random number generator fails, as crypto/rand does itself
+// from Go 1.24.
+func GenerateSecret() string {
+	b := make([]byte, 48)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("generating secret: %w", err))
+	}
+	return base64Encode(b)
+}
+
+// ErrWeakSecret is returned, wrapped, by CheckSecretStrength and
+// StrictDerivation when a secret is too weak.
+var ErrWeakSecret = errors.New("secret is too weak")
+
+// CheckSecretStrength returns an error wrapping ErrWeakSecret if the secret
+// has less estimated entropy than the output size of the given digest, up to
+// a maximum of 256 bits. The estimate assumes each character was chosen at
+// random from the smallest common alphabet containing the characters used:
+// decimal or hexadecimal digits, base64, printable ASCII or, for binary
+// secrets, any byte. So a random key of the digest's size passes, whether
+// raw, hex or base64 encoded. A secret which repeats a shorter string is
+// credited only for that string, and none is credited more than its size
+// once compressed. It is still generous to human-chosen secrets; the check
+// catches short, empty and repetitive secrets rather than proving a secret
+// is strong. Use GenerateSecret to create secrets.
+func CheckSecretStrength(secret string, digest func() hash.Hash) error {
+	required := digest().Size() * 8
+	if required > 256 {
+		required = 256
+	}
+	if bits := secretEntropy(secret); bits < float64(required) {
+		return fmt.Errorf("%w: estimated %.0f bits of entropy, at least %d are required", ErrWeakSecret, bits, required)
+	}
+	return nil
+}
+
+// secretEntropy estimates the entropy of the secret in bits from its length
+// and the alphabet it uses.
+func secretEntropy(secret string) float64 {
+	// Compression has too much overhead to catch short repetitions, so
+	// check for those exactly. Only a secret which repeats a string at
+	// least twice counts, as a random one often ends with a few of the
+	// characters it starts with.
+	if period := secretPeriod(secret); 2*period <= len(secret) {
+		secret = secret[:period]
+	}
+	if secret == "" {
+		return 0
+	}
+	return math.Min(float64(len(secret))*math.Log2(float64(alphabetSize(secret))), compressedBits(secret))
+}
+
+// alphabetSize returns the size of the smallest common alphabet which
+// contains every character of the secret.
+func alphabetSize(secret string) int {
+	size := 10
+	for i := 0; i < len(secret); i++ {
+		switch c := secret[i]; {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F':
+			if size < 16 {
+				size = 16
+			}
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || strings.IndexByte("-_+/=", c) >= 0:
+			if size < 64 {
+				size = 64
+			}
+		case c >= ' ' && c < 0x7f:
+			if size < 95 {
+				size = 95
+			}
+		default:
+			return 256
+		}
+	}
+	return size
+}
+
+// secretPeriod returns the length of the shortest string which the secret is
+// a repetition of, such as 3 for "abcabcab".
+func secretPeriod(secret string) int {
+	if secret == "" {
+		return 0
+	}
+	// border[i] is the length of the longest proper prefix of secret[:i+1]
+	// which is also a suffix of it, as in the Knuth-Morris-Pratt algorithm.
+	border := make([]int, len(secret))
+	for i := 1; i < len(secret); i++ {
+		k := border[i-1]
+		for k > 0 && secret[i] != secret[k] {
+			k = border[k-1]
+		}
+		if secret[i] == secret[k] {
+			k++
+		}
+		border[i] = k
+	}
+	return len(secret) - border[len(secret)-1]
+}
+
+// compressedBits returns the size in bits of the secret compressed with
+// DEFLATE, which bounds its entropy as repetition makes it compressible.
+func compressedBits(secret string) float64 {
+	var b bytes.Buffer
+	// NewWriter only fails for an invalid level.
+	w, _ := flate.NewWriter(&b, flate.BestCompression)
+	w.Write([]byte(secret))
+	w.Close()
+	return float64(8 * b.Len())
+}
+
+// StrictDerivation wraps a KeyDerivation, rejecting secrets which fail
+// CheckSecretStrength for the digest in use before deriving the key. If
+// KeyDerivation is nil it uses DjangoConcatDerivation, the default.
+type StrictDerivation struct {
+	KeyDerivation KeyDerivation
+}
+
+// DeriveKey returns the derived key for the given secret and salt, or an
+// error if the secret is too weak.
+func (d StrictDerivation) DeriveKey(secret, salt string, digest func() hash.Hash) ([]byte, error) {
+	if err := CheckSecretStrength(secret, digest); err != nil {
+		return nil, err
+	}
+	if d.KeyDerivation == nil {
+		return DjangoConcatDerivation{}.DeriveKey(secret, salt, digest)
+	}
+	return d.KeyDerivation.DeriveKey(secret, salt, digest)
+}
+
+// NewStrictSigner works like NewSigner but returns an error wrapping
+// ErrWeakSecret rather than accepting a weak secret. Use StrictDerivation
+// with NewSignerWithKeyDerivation to check secrets with other options.
+func NewStrictSigner(secret, salt string) (*Signer, error) {
+	return NewSignerWithKeyDerivation(secret, salt, "", StrictDerivation{}, nil, nil)
+}
+
+// NewStrictTimestampSigner works like NewTimestampSigner but returns an error
+// wrapping ErrWeakSecret rather than accepting a weak secret.
+func NewStrictTimestampSigner(secret, salt string) (*TimestampSigner, error) {
+	return NewTimestampSignerWithKeyDerivation(secret, salt, "", StrictDerivation{}, nil, nil)
+}
+
+// SecretEncoding is how a secret loaded by a SecretSource is encoded.
+type SecretEncoding string
+
+const (
+	// RawSecret secrets are used as is.
+	RawSecret SecretEncoding = ""
+	// Base64Secret secrets are decoded from standard or URL-safe base64,
+	// with or without padding.
+	Base64Secret SecretEncoding = "base64"
+	// HexSecret secrets are decoded from hexadecimal.
+	HexSecret SecretEncoding = "hex"
+)
+
+// SecretSource loads a secret, eg from the environment or a file, so secrets
+// need not be handled by the program itself.
+type SecretSource func() (string, error)
+
+// FromEnv returns a SecretSource which reads the secret from the named
+// environment variable, decoding it with the given encoding. It fails if the
+// variable is unset or empty.
+func FromEnv(name string, encoding SecretEncoding) SecretSource {
+	return func() (string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret environment variable %s is not set", name)
+		}
+		secret, err := decodeSecret(value, encoding)
+		if err != nil {
+			return "", fmt.Errorf("secret environment variable %s: %w", name, err)
+		}
+		return secret, nil
+	}
+}
+
+// FromFile returns a SecretSource which reads the secret from the file at
+// path, decoding it with the given encoding. Trailing newlines are removed,
+// so files written by editors or echo work as expected. It fails if the file
+// is missing or empty.
+func FromFile(path string, encoding SecretEncoding) SecretSource {
+	return func() (string, error) {
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading secret file: %w", err)
+		}
+		secret, err := decodeSecret(string(value), encoding)
+		if err != nil {
+			return "", fmt.Errorf("secret file %s: %w", path, err)
+		}
+		return secret, nil
+	}
+}
+
+// decodeSecret trims trailing newlines from the secret and decodes it.
+func decodeSecret(value string, encoding SecretEncoding) (string, error) {
+	value = strings.TrimRight(value, "\r\n")
+	if value == "" {
+		return "", errors.New("secret is empty")
+	}
+
+	switch encoding {
+	case RawSecret:
+		return value, nil
+	case Base64Secret:
+		// Accept any of the common variants.
+		value = strings.TrimRight(value, "=")
+		value = strings.NewReplacer("+", "-", "/", "_").Replace(value)
+		b, err := base64Decode(value)
+		if err != nil {
+			return "", fmt.Errorf("decoding base64 secret: %w", err)
+		}
+		return string(b), nil
+	case HexSecret:
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return "", fmt.Errorf("decoding hex secret: %w", err)
+		}
+		return string(b), nil
+	}
+	return "", fmt.Errorf("unknown secret encoding %q", encoding)
+}
+
+// Key loads the secret and returns it as a Key for a Keyring with the given
+// ID.
+func (s SecretSource) Key(id string) (Key, error) {
+	secret, err := s()
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{ID: id, Secret: secret}, nil
+}
+
+// NewSignerFromSource works like NewSignerWithOptions but loads the secret
+// from the given source.
+func NewSignerFromSource(source SecretSource, salt, sep, derivation string, digest func() hash.Hash, algo SigningAlgorithm) (*Signer, error) {
+	secret, err := source()
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerWithOptions(secret, salt, sep, derivation, digest, algo)
+}
+
+// NewTimestampSignerFromSource works like NewTimestampSignerWithOptions but
+// loads the secret from the given source.
+func NewTimestampSignerFromSource(source SecretSource, salt, sep, derivation string, digest func() hash.Hash, algo SigningAlgorithm) (*TimestampSigner, error) {
+	secret, err := source()
+	if err != nil {
+		return nil, err
+	}
+	return NewTimestampSignerWithOptions(secret, salt, sep, derivation, digest, algo)
+}
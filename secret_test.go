@@ -0,0 +1,215 @@
+package itsdangerous_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/junohq/go-itsdangerous"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	a, b := itsdangerous.GenerateSecret(), itsdangerous.GenerateSecret()
+	if len(a) != 64 {
+		t.Errorf("GenerateSecret() got %s of length %d; want 64", a, len(a))
+	}
+	if a == b {
+		t.Errorf("GenerateSecret() returned the same secret twice")
+	}
+	for i := 0; i < 500; i++ {
+		secret := itsdangerous.GenerateSecret()
+		for _, digest := range []func() hash.Hash{sha1.New, sha256.New, sha512.New} {
+			if err := itsdangerous.CheckSecretStrength(secret, digest); err != nil {
+				t.Fatalf("CheckSecretStrength(%s) returned error: %s", secret, err)
+			}
+		}
+	}
+	// Even if it happens to use only letters.
+	letters := "NqSLbMDQLiRjQcEXHhynZYqniSsrCbDxWDCEkTvPoUaGfJmRwBtYzLpKeOsHdVnA"
+	if err := itsdangerous.CheckSecretStrength(letters, sha512.New); err != nil {
+		t.Errorf("CheckSecretStrength(%s) returned error: %s", letters, err)
+	}
+}
+
+func TestCheckSecretStrength(t *testing.T) {
+	tests := []struct {
+		secret       string
+		digest       func() hash.Hash
+		expectStrong bool
+	}{
+		{secret: "", digest: sha1.New},
+		{secret: "s", digest: sha1.New},
+		{secret: "secret_key", digest: sha1.New},
+		{secret: strings.Repeat("a", 35), digest: sha1.New},
+		{secret: strings.Repeat("a", 1000), digest: sha1.New},
+		{secret: strings.Repeat("ab", 500), digest: sha1.New},
+		{secret: strings.Repeat("password", 100), digest: sha1.New},
+		{secret: strings.Repeat("ab", 100) + "c", digest: sha1.New},
+		{secret: "0123456789abcdef0123456789abcdef0123456789", digest: sha1.New},
+		{secret: "3f9a1c0e7b5d24f86a0c9e1b7d3f5a2c8e4b6d01", digest: sha1.New, expectStrong: true},
+		{secret: "NqSL4bMDQLiR7jQcEXHhyn9ZYqn8i_Ss3rCbDxWDC0E", digest: sha512.New, expectStrong: true},
+		{secret: "\x8f\x12\xe5\x01\xaa\x93\x7b\x44\xd0\x5c\x0e\xf1\x22\x9a\x6b\x37" +
+			"\xc4\x08\x51\xbe\x7f\x2d\x90\x63\x1a\xe7\x3c\x85\xf4\x0b\x69\xd2", digest: sha256.New, expectStrong: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.secret, func(t *testing.T) {
+			err := itsdangerous.CheckSecretStrength(test.secret, test.digest)
+			if test.expectStrong {
+				if err != nil {
+					t.Errorf("CheckSecretStrength(%q) returned error: %s", test.secret, err)
+				}
+			} else if !errors.Is(err, itsdangerous.ErrWeakSecret) {
+				t.Errorf("CheckSecretStrength(%q) expected ErrWeakSecret; got %v", test.secret, err)
+			}
+		})
+	}
+}
+
+func TestCheckSecretStrengthRandomKeys(t *testing.T) {
+	for _, digest := range []func() hash.Hash{sha1.New, sha256.New, sha512.New} {
+		key := make([]byte, digest().Size())
+		for i := 0; i < 300; i++ {
+			if _, err := rand.Read(key); err != nil {
+				t.Fatalf("rand.Read returned error: %s", err)
+			}
+			for _, secret := range []string{string(key), hex.EncodeToString(key), base64.RawURLEncoding.EncodeToString(key)} {
+				if _, err := itsdangerous.NewSignerWithKeyDerivation(secret, "salt", "", itsdangerous.StrictDerivation{}, digest, nil); err != nil {
+					t.Fatalf("NewSignerWithKeyDerivation(%q) with StrictDerivation returned error: %s", secret, err)
+				}
+			}
+		}
+	}
+	// NewStrictSigner uses SHA-1, so a random 20 byte key is enough.
+	key := make([]byte, sha1.Size)
+	for i := 0; i < 300; i++ {
+		if _, err := rand.Read(key); err != nil {
+			t.Fatalf("rand.Read returned error: %s", err)
+		}
+		if _, err := itsdangerous.NewStrictSigner(string(key), "salt"); err != nil {
+			t.Fatalf("NewStrictSigner(%x) returned error: %s", key, err)
+		}
+	}
+}
+
+func TestNewStrictSigner(t *testing.T) {
+	if _, err := itsdangerous.NewStrictSigner("secret_key", "salt"); !errors.Is(err, itsdangerous.ErrWeakSecret) {
+		t.Errorf("NewStrictSigner(secret_key) expected ErrWeakSecret; got %v", err)
+	}
+	if _, err := itsdangerous.NewStrictTimestampSigner("", "salt"); !errors.Is(err, itsdangerous.ErrWeakSecret) {
+		t.Errorf("NewStrictTimestampSigner() expected ErrWeakSecret; got %v", err)
+	}
+
+	secret := itsdangerous.GenerateSecret()
+	strict, err := itsdangerous.NewStrictSigner(secret, "salt")
+	if err != nil {
+		t.Fatalf("NewStrictSigner(%s) returned error: %s", secret, err)
+	}
+	// Strict signers derive the same key as regular signers.
+	signed := strict.Sign("my string")
+	if _, err := itsdangerous.NewSigner(secret, "salt").Unsign(signed); err != nil {
+		t.Errorf("Unsign(%s) returned error: %s", signed, err)
+	}
+
+	_, err = itsdangerous.NewSignerWithKeyDerivation("secret_key", "salt", "",
+		itsdangerous.StrictDerivation{KeyDerivation: itsdangerous.HMACDerivation{}}, sha512.New, nil)
+	if !errors.Is(err, itsdangerous.ErrWeakSecret) {
+		t.Errorf("NewSignerWithKeyDerivation with StrictDerivation expected ErrWeakSecret; got %v", err)
+	}
+}
+
+func TestSecretSources(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	t.Setenv("ITSDANGEROUS_RAW", "secret_key")
+	t.Setenv("ITSDANGEROUS_BASE64", "c2VjcmV0X2tleQ==")
+	t.Setenv("ITSDANGEROUS_EMPTY", "")
+
+	tests := []struct {
+		name     string
+		source   itsdangerous.SecretSource
+		expected string
+	}{
+		{name: "raw env", source: itsdangerous.FromEnv("ITSDANGEROUS_RAW", itsdangerous.RawSecret), expected: "secret_key"},
+		{name: "base64 env", source: itsdangerous.FromEnv("ITSDANGEROUS_BASE64", itsdangerous.Base64Secret), expected: "secret_key"},
+		{name: "raw file", source: itsdangerous.FromFile(write("raw", "secret_key\n"), itsdangerous.RawSecret), expected: "secret_key"},
+		{name: "url-safe base64 file", source: itsdangerous.FromFile(write("base64", "-_8\r\n"), itsdangerous.Base64Secret), expected: "\xfb\xff"},
+		{name: "standard base64 file", source: itsdangerous.FromFile(write("std", "+/8=\n"), itsdangerous.Base64Secret), expected: "\xfb\xff"},
+		{name: "hex file", source: itsdangerous.FromFile(write("hex", "736563726574\n"), itsdangerous.HexSecret), expected: "secret"},
+		{name: "unset env", source: itsdangerous.FromEnv("ITSDANGEROUS_UNSET", itsdangerous.RawSecret)},
+		{name: "empty env", source: itsdangerous.FromEnv("ITSDANGEROUS_EMPTY", itsdangerous.RawSecret)},
+		{name: "missing file", source: itsdangerous.FromFile(filepath.Join(dir, "missing"), itsdangerous.RawSecret)},
+		{name: "empty file", source: itsdangerous.FromFile(write("empty", "\n"), itsdangerous.RawSecret)},
+		{name: "invalid hex", source: itsdangerous.FromFile(write("invalid", "secret"), itsdangerous.HexSecret)},
+		{name: "unknown encoding", source: itsdangerous.FromEnv("ITSDANGEROUS_RAW", "base32")},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.source()
+			if test.expected == "" {
+				if err == nil {
+					t.Errorf("source expected error; got %q", actual)
+				}
+			} else if err != nil {
+				t.Errorf("source returned error: %s", err)
+			} else if actual != test.expected {
+				t.Errorf("source got %q; want %q", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewSignerFromSource(t *testing.T) {
+	t.Setenv("ITSDANGEROUS_SECRET", "c2VjcmV0X2tleQ")
+	source := itsdangerous.FromEnv("ITSDANGEROUS_SECRET", itsdangerous.Base64Secret)
+
+	sig, err := itsdangerous.NewSignerFromSource(source, "salt", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("NewSignerFromSource returned error: %s", err)
+	}
+	if actual := sig.Sign("my string"); actual != "my string.xv0r21ogoygusbkJA01c4OxsAio" {
+		t.Errorf("Sign(my string) got %s; want my string.xv0r21ogoygusbkJA01c4OxsAio", actual)
+	}
+
+	key, err := source.Key("current")
+	if err != nil {
+		t.Fatalf("Key returned error: %s", err)
+	}
+	keyring, err := itsdangerous.NewKeyring([]itsdangerous.Key{key}, "salt")
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %s", err)
+	}
+	ts, err := itsdangerous.NewTimestampSignerWithKeyring(keyring, "")
+	if err != nil {
+		t.Fatalf("NewTimestampSignerWithKeyring returned error: %s", err)
+	}
+	if _, err := ts.UnsignContext(context.Background(), signContext(t, ts, "my string"), 0); err != nil {
+		t.Errorf("UnsignContext returned error: %s", err)
+	}
+
+	missing := itsdangerous.FromEnv("ITSDANGEROUS_UNSET", itsdangerous.RawSecret)
+	if _, err := itsdangerous.NewTimestampSignerFromSource(missing, "salt", "", "", nil, nil); err == nil ||
+		!strings.Contains(err.Error(), "ITSDANGEROUS_UNSET") {
+		t.Errorf("NewTimestampSignerFromSource expected error naming the variable; got %v", err)
+	}
+	if _, err := missing.Key("current"); err == nil {
+		t.Errorf("Key expected error for unset variable")
+	}
+}
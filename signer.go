@@ -2,12 +2,13 @@ package itsdangerous
 
 import (
 	"bytes"
-	"crypto/hmac"
+	"context"
 	"crypto/sha1"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"hash"
+	"math"
 	"strings"
 	"time"
 )
@@ -20,10 +21,19 @@ import (
 // a salt value across different parts of your application where the same
 // signed value in one part can mean something different in another part
 // is a security risk.
+//
+// If RevocationChecker is set, Unsign rejects tokens whose signature has been
+// revoked with a RevokedError.
+//
+// If ChildCache is set, WithSalt caches the keys of the child signers it
+// creates there. See NewParentSigner.
 type Signer struct {
-	sep       string
-	key       []byte
-	algorithm SigningAlgorithm
+	sep     string
+	backend SigningBackend
+	secret  *signerSecret
+
+	RevocationChecker RevocationChecker
+	ChildCache        *ChildCache
 }
 
 // NewSigner creates a new Signer with the given secret and salt. All other
@@ -38,16 +48,39 @@ func NewSigner(secret, salt string) *Signer {
 }
 
 // NewSignerWithOptions creates a new Signer allowing overiding the default
-// properties.
+// properties. The derivation is given by name, one of "concat",
+// "django-concat", "hmac", "none", "hkdf" or "pbkdf2".
 func NewSignerWithOptions(secret, salt, sep, derivation string, digest func() hash.Hash, algo SigningAlgorithm) (*Signer, error) {
+	if derivation == "" {
+		derivation = "django-concat"
+	}
+	d, err := KeyDerivationByName(derivation)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerWithKeyDerivation(secret, salt, sep, d, digest, algo)
+}
+
+// NewSignerWithKeyDerivation works like NewSignerWithOptions but takes the
+// key derivation as a KeyDerivation, allowing custom implementations.
+func NewSignerWithKeyDerivation(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm) (*Signer, error) {
+	return newSecretSigner(secret, salt, sep, derivation, digest, algo, false)
+}
+
+// NewParentSigner works like NewSignerWithKeyDerivation but keeps the secret,
+// so that WithSalt can create child signers with other salts. Other signers
+// only keep the key derived from it, so this should only be used where
+// children are needed.
+func NewParentSigner(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm) (*Signer, error) {
+	return newSecretSigner(secret, salt, sep, derivation, digest, algo, true)
+}
+
+func newSecretSigner(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm, parent bool) (*Signer, error) {
 	if salt == "" {
 		salt = "itsdangerous.Signer"
 	}
-	if sep == "" {
-		sep = "."
-	}
-	if derivation == "" {
-		derivation = "django-concat"
+	if derivation == nil {
+		derivation = DjangoConcatDerivation{}
 	}
 	if digest == nil {
 		digest = sha1.New
@@ -55,82 +88,183 @@ func NewSignerWithOptions(secret, salt, sep, derivation string, digest func() ha
 	if algo == nil {
 		algo = &HMACAlgorithm{DigestMethod: digest}
 	}
-	s := &Signer{
-		sep:       sep,
-		algorithm: algo,
-	}
-	var err error
-	s.key, err = deriveKey(secret, salt, derivation, digest)
-	return s, err
-}
-
-// deriveKey generates a key derivation. Keep in mind that the key derivation in itsdangerous
-// is not intended to be used as a security method to make a complex key out of a short password.
-// Instead you should use large random secret keys.
-func deriveKey(secretKey, salt, keyDerivation string, digestMethod func() hash.Hash) ([]byte, error) {
-	var key []byte
-	var err error
-
-	switch keyDerivation {
-	case "concat":
-		h := digestMethod()
-		h.Write([]byte(salt + secretKey))
-		key = h.Sum(nil)
-	case "django-concat":
-		h := digestMethod()
-		h.Write([]byte(salt + "signer" + secretKey))
-		key = h.Sum(nil)
-	case "hmac":
-		h := hmac.New(digestMethod, []byte(secretKey))
-		h.Write([]byte(salt))
-		key = h.Sum(nil)
-	case "none":
-		key = []byte(secretKey)
-	default:
-		err = errors.New("unknown key derivation method " + keyDerivation)
-	}
-	return key, err
+	key, err := derivation.DeriveKey(secret, salt, digest)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSigner(&LocalBackend{Key: key, Algorithm: algo}, sep)
+	if err != nil {
+		return nil, err
+	}
+	if parent {
+		s.secret = &signerSecret{secret: secret, derivation: derivation, digest: digest, algo: algo}
+	}
+	return s, nil
+}
+
+// newSigner creates a new Signer which signs using the given backend. Only
+// backends which can't fail, such as LocalBackend, may be used, as Sign
+// can't return an error; others need a BackendSigner.
+func newSigner(backend SigningBackend, sep string) (*Signer, error) {
+	sep, err := checkSep(sep)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{sep: sep, backend: backend}, nil
+}
+
+// bound returns a copy of the signer whose key is additionally derived from
+// the given binding data, so signatures only verify when the same data is
+// supplied again.
+func (s Signer) bound(binding []string) (*Signer, error) {
+	if len(binding) == 0 {
+		return &s, nil
+	}
+	b, ok := s.backend.(binder)
+	if !ok {
+		return nil, errors.New("signing backend does not support binding data")
+	}
+	parts := make([]string, len(binding))
+	for i, b := range binding {
+		parts[i] = base64Encode([]byte(b))
+	}
+	s.backend = b.bind("bind" + s.sep + strings.Join(parts, s.sep))
+	return &s, nil
 }
 
 // getSignature returns the signature for the given value.
-func (s *Signer) getSignature(value string) string {
-	sig := s.algorithm.GetSignature(s.key, value)
-	return base64Encode(sig)
+func (s *Signer) getSignature(ctx context.Context, value string) (string, error) {
+	sig, err := s.backend.Sign(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return base64Encode(sig), nil
 }
 
-// verifySignature verifies the signature for the given value.
-func (s *Signer) verifySignature(value, signature string) (bool, error) {
+// verifySignature verifies the signature for the given value, returning the
+// key which made it if the backend reports one.
+func (s *Signer) verifySignature(ctx context.Context, value, signature string) (*Key, error) {
 	signed, err := base64Decode(signature)
 	if err != nil {
-		return false, err
+		return nil, InvalidSignatureError{errors.New("signature does not match")}
+	}
+	if kb, ok := s.backend.(keyedBackend); ok {
+		return kb.verifyKey(ctx, value, signed)
 	}
-	return s.algorithm.VerifySignature(s.key, value, signed), nil
+	ok, err := s.backend.Verify(ctx, value, signed)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, InvalidSignatureError{errors.New("signature does not match")}
+	}
+	return nil, nil
 }
 
 // Sign the given string.
 func (s *Signer) Sign(value string) string {
-	sig := s.getSignature(value)
-	return value + s.sep + sig
+	return mustSign(s.SignContext(context.Background(), value))
+}
+
+// SignContext signs the given string, passing ctx to the signing backend.
+func (s *Signer) SignContext(ctx context.Context, value string) (string, error) {
+	sig, err := s.getSignature(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return value + s.sep + sig, nil
 }
 
 // Unsign the given string.
 func (s *Signer) Unsign(signed string) (string, error) {
+	return s.UnsignContext(context.Background(), signed)
+}
+
+// UnsignContext unsigns the given string, passing ctx to the signing backend.
+func (s *Signer) UnsignContext(ctx context.Context, signed string) (string, error) {
+	value, _, err := s.unsign(ctx, signed)
+	return value, err
+}
+
+// unsign works like Unsign but also returns the key which verified the
+// signature, if the backend reports one.
+func (s *Signer) unsign(ctx context.Context, signed string) (string, *Key, error) {
 	li := strings.LastIndex(signed, s.sep)
 	if li < 0 {
-		return "", InvalidSignatureError{fmt.Errorf("no %s found in value", s.sep)}
+		return "", nil, InvalidSignatureError{fmt.Errorf("no %s found in value", s.sep)}
 	}
 	value, sig := signed[:li], signed[li+len(s.sep):]
 
-	if ok, _ := s.verifySignature(value, sig); ok == true {
-		return value, nil
+	key, err := s.verifyDetached(ctx, value, sig)
+	if err != nil {
+		return "", nil, err
 	}
-	return "", InvalidSignatureError{fmt.Errorf("signature does not match")}
+	return value, key, nil
+}
+
+// SignDetached returns only the signature for the given string, so the value
+// and its signature can be transported separately.
+func (s *Signer) SignDetached(value string) string {
+	return mustSign(s.SignDetachedContext(context.Background(), value))
+}
+
+// SignDetachedContext works like SignDetached but passes ctx to the signing
+// backend.
+func (s *Signer) SignDetachedContext(ctx context.Context, value string) (string, error) {
+	return s.getSignature(ctx, value)
+}
+
+// VerifyDetached verifies a signature produced by SignDetached for the given
+// string.
+func (s *Signer) VerifyDetached(value, sig string) error {
+	return s.VerifyDetachedContext(context.Background(), value, sig)
+}
+
+// VerifyDetachedContext works like VerifyDetached but passes ctx to the
+// signing backend.
+func (s *Signer) VerifyDetachedContext(ctx context.Context, value, sig string) error {
+	_, err := s.verifyDetached(ctx, value, sig)
+	return err
+}
+
+func (s *Signer) verifyDetached(ctx context.Context, value, sig string) (*Key, error) {
+	key, err := s.verifySignature(ctx, value, sig)
+	if err != nil {
+		return nil, err
+	}
+	if s.RevocationChecker != nil {
+		revoked, err := s.RevocationChecker.IsRevoked(sig)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, signatureRevoked(sig)
+		}
+	}
+	return key, nil
+}
+
+// mustSign panics if signing failed, which can't happen with the LocalBackend
+// every Signer uses. Signing with other backends, which may fail, is only
+// possible through the methods of BackendSigner, which return the error.
+func mustSign(signed string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+	return signed
 }
 
 // TimestampSigner works like the regular Signer but also records the time
 // of the signing and can be used to expire signatures.
 type TimestampSigner struct {
 	Signer
+
+	// MillisecondTimestamps records timestamps with millisecond rather than
+	// second precision, so short maxAge values are checked precisely.
+	// Millisecond timestamps are marked as such, so signatures made with
+	// this set are rejected by a TimestampSigner which does not have it set
+	// and vice versa. They are not understood by Python itsdangerous.
+	MillisecondTimestamps bool
 }
 
 // NewTimestampSigner creates a new TimestampSigner with the given secret and
@@ -151,52 +285,338 @@ func NewTimestampSignerWithOptions(secret, salt, sep, derivation string, digest
 	return &TimestampSigner{Signer: *s}, nil
 }
 
+// NewTimestampSignerWithKeyDerivation works like
+// NewTimestampSignerWithOptions but takes the key derivation as a
+// KeyDerivation, allowing custom implementations.
+func NewTimestampSignerWithKeyDerivation(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm) (*TimestampSigner, error) {
+	s, err := NewSignerWithKeyDerivation(secret, salt, sep, derivation, digest, algo)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampSigner{Signer: *s}, nil
+}
+
+// NewParentTimestampSigner works like NewTimestampSignerWithKeyDerivation but
+// keeps the secret, so that WithSalt can create children. See
+// NewParentSigner.
+func NewParentTimestampSigner(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm) (*TimestampSigner, error) {
+	s, err := NewParentSigner(secret, salt, sep, derivation, digest, algo)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampSigner{Signer: *s}, nil
+}
+
 // Sign the given string.
 func (s *TimestampSigner) Sign(value string) string {
-	tsBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(tsBytes, uint64(getTimestamp()))
-	// trim leading zeroes
-	tsBytes = bytes.TrimLeft(tsBytes, "\x00")
+	return mustSign(s.SignContext(context.Background(), value))
+}
+
+// SignContext signs the given string, passing ctx to the signing backend.
+func (s *TimestampSigner) SignContext(ctx context.Context, value string) (string, error) {
+	return s.Signer.SignContext(ctx, value+s.sep+s.timestamp())
+}
 
-	ts := base64Encode(tsBytes)
-	val := value + s.sep + ts
+// SignWithExpiry works like Sign but also records that the signature expires
+// ttl after signing. Unsign rejects the signature once it has expired, even
+// when called with a zero maxAge.
+//
+// Signatures with an expiry are not understood by Python itsdangerous.
+func (s *TimestampSigner) SignWithExpiry(value string, ttl time.Duration) string {
+	return mustSign(s.SignWithExpiryContext(context.Background(), value, ttl))
+}
 
-	return s.Signer.Sign(val)
+// SignWithExpiryContext works like SignWithExpiry but passes ctx to the
+// signing backend.
+func (s *TimestampSigner) SignWithExpiryContext(ctx context.Context, value string, ttl time.Duration) (string, error) {
+	return s.Signer.SignContext(ctx, value+s.sep+s.expiryTimestamp(ttl))
 }
 
-// Unsign the given string.
+// SignWithNotBefore works like Sign but also records that the signature is not
+// valid until notBefore. Unsign rejects the signature with a NotYetValidError
+// before then. If ttl is greater than zero the signature also expires ttl
+// after notBefore.
+//
+// Signatures with a not-before time are not understood by Python
+// itsdangerous.
+func (s *TimestampSigner) SignWithNotBefore(value string, notBefore time.Time, ttl time.Duration) string {
+	return mustSign(s.SignWithNotBeforeContext(context.Background(), value, notBefore, ttl))
+}
+
+// SignWithNotBeforeContext works like SignWithNotBefore but passes ctx to the
+// signing backend.
+func (s *TimestampSigner) SignWithNotBeforeContext(ctx context.Context, value string, notBefore time.Time, ttl time.Duration) (string, error) {
+	return s.Signer.SignContext(ctx, value+s.sep+s.notBeforeTimestamp(notBefore, ttl))
+}
+
+// Unsign the given string. If maxAge is greater than zero, signatures older
+// than maxAge are rejected. Signatures with an expiry recorded by
+// SignWithExpiry are additionally rejected once they expire, so maxAge acts
+// as a cap on their lifetime, and those with a not-before time recorded by
+// SignWithNotBefore are rejected until that time.
 func (s *TimestampSigner) Unsign(value string, maxAge time.Duration) (string, error) {
-	result, err := s.Signer.Unsign(value)
+	return s.UnsignContext(context.Background(), value, maxAge)
+}
+
+// UnsignContext unsigns the given string, passing ctx to the signing backend.
+func (s *TimestampSigner) UnsignContext(ctx context.Context, value string, maxAge time.Duration) (string, error) {
+	val, _, _, err := s.unsign(ctx, value, maxAge)
+	return val, err
+}
+
+// unsign works like Unsign but also returns the time of signing and the key
+// which verified the signature, if the backend reports one.
+func (s *TimestampSigner) unsign(ctx context.Context, value string, maxAge time.Duration) (string, time.Time, *Key, error) {
+	result, key, err := s.Signer.unsign(ctx, value)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, nil, err
 	}
 
 	li := strings.LastIndex(result, s.sep)
 	if li < 0 {
 		// If there is no timestamp in the result there is something seriously wrong.
-		return "", InvalidSignatureError{errors.New("timestamp missing")}
+		return "", time.Time{}, nil, InvalidSignatureError{errors.New("timestamp missing")}
 	}
 	val, ts := result[:li], result[li+len(s.sep):]
 
-	tsBytes, err := base64Decode(ts)
+	signed, err := s.checkTimestamp(ts, maxAge)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	if err := s.checkKeyWindow(key, signed); err != nil {
+		return "", time.Time{}, nil, err
+	}
+	return val, signed, key, nil
+}
+
+// SignDetached returns the timestamp and signature for the given string,
+// joined by the separator, without the value itself.
+func (s *TimestampSigner) SignDetached(value string) string {
+	return mustSign(s.SignDetachedContext(context.Background(), value))
+}
+
+// SignDetachedContext works like SignDetached but passes ctx to the signing
+// backend.
+func (s *TimestampSigner) SignDetachedContext(ctx context.Context, value string) (string, error) {
+	ts := s.timestamp()
+	sig, err := s.Signer.SignDetachedContext(ctx, value+s.sep+ts)
 	if err != nil {
 		return "", err
 	}
-	// left pad up to 8 bytes
-	if len(tsBytes) < 8 {
+	return ts + s.sep + sig, nil
+}
+
+// VerifyDetached verifies a timestamp and signature produced by SignDetached
+// for the given string.
+func (s *TimestampSigner) VerifyDetached(value, sig string, maxAge time.Duration) error {
+	return s.VerifyDetachedContext(context.Background(), value, sig, maxAge)
+}
+
+// VerifyDetachedContext works like VerifyDetached but passes ctx to the
+// signing backend.
+func (s *TimestampSigner) VerifyDetachedContext(ctx context.Context, value, sig string, maxAge time.Duration) error {
+	li := strings.LastIndex(sig, s.sep)
+	if li < 0 {
+		return InvalidSignatureError{errors.New("timestamp missing")}
+	}
+	ts, sig := sig[:li], sig[li+len(s.sep):]
+
+	key, err := s.Signer.verifyDetached(ctx, value+s.sep+ts, sig)
+	if err != nil {
+		return err
+	}
+	signed, err := s.checkTimestamp(ts, maxAge)
+	if err != nil {
+		return err
+	}
+	return s.checkKeyWindow(key, signed)
+}
+
+// checkKeyWindow checks the time of signing is within the validity window of
+// the key which verified the signature, if any.
+func (s *TimestampSigner) checkKeyWindow(key *Key, signed time.Time) error {
+	if key == nil {
+		return nil
+	}
+	// Timestamps are truncated, so truncate the window to match.
+	precision := s.precision()
+	if !key.NotBefore.IsZero() && signed.Before(key.NotBefore.Truncate(precision)) ||
+		!key.NotAfter.IsZero() && signed.After(key.NotAfter) {
+		return InvalidSignatureError{fmt.Errorf("signature time %s is outside the validity window of key %q", signed.Format(time.RFC3339), key.ID)}
+	}
+	return nil
+}
+
+// timestamp returns the encoded current timestamp.
+func (s *TimestampSigner) timestamp() string {
+	return s.encodeTimestamps(s.toTimestamp(NowFunc()))
+}
+
+// expiryTimestamp returns the encoded current timestamp with an expiry ttl
+// from now.
+func (s *TimestampSigner) expiryTimestamp(ttl time.Duration) string {
+	now := s.toTimestamp(NowFunc())
+	return s.encodeTimestamps(now, now+int64(ttl/s.precision()))
+}
+
+// notBeforeTimestamp returns the encoded current timestamp with the given
+// not-before time, and an expiry ttl after that if ttl is greater than zero.
+func (s *TimestampSigner) notBeforeTimestamp(notBefore time.Time, ttl time.Duration) string {
+	nbf := s.toTimestamp(notBefore)
+	var expires int64
+	if ttl > 0 {
+		expires = nbf + int64(ttl/s.precision())
+	}
+	return s.encodeTimestamps(s.toTimestamp(NowFunc()), expires, nbf)
+}
+
+// precision returns the unit timestamps are recorded in.
+func (s *TimestampSigner) precision() time.Duration {
+	if s.MillisecondTimestamps {
+		return time.Millisecond
+	}
+	return time.Second
+}
+
+// toTimestamp converts t to a timestamp in the signer's precision.
+func (s *TimestampSigner) toTimestamp(t time.Time) int64 {
+	if s.MillisecondTimestamps {
+		return t.UnixMilli()
+	}
+	return t.Unix()
+}
+
+// fromTimestamp converts a timestamp in the signer's precision to a time.
+func (s *TimestampSigner) fromTimestamp(ts int64) time.Time {
+	if s.MillisecondTimestamps {
+		return time.UnixMilli(ts)
+	}
+	return time.Unix(ts, 0)
+}
+
+// millisecondMarker is the first byte of encoded millisecond timestamps. It
+// makes the encoded length one more than a multiple of 8, so a verifier
+// expecting second timestamps rejects them as malformed rather than misreading
+// them, and a verifier expecting millisecond timestamps can tell when it has
+// been given second timestamps.
+const millisecondMarker = 'm'
+
+// futureTolerance is how far in the future a signing time may be, allowing
+// for clock skew between servers, before a signature with a maxAge or expiry
+// is rejected.
+const futureTolerance = time.Minute
+
+// encodeTimestamps encodes the time of signing and any further times recorded
+// with it. A lone second timestamp is encoded as in Python itsdangerous, with
+// leading zero bytes trimmed. Otherwise each timestamp takes a full 8 bytes, so
+// the decoded length tells the formats apart, and millisecond timestamps are
+// preceded by millisecondMarker.
+func (s *TimestampSigner) encodeTimestamps(timestamps ...int64) string {
+	tsBytes := make([]byte, 8*len(timestamps))
+	for i, ts := range timestamps {
+		binary.BigEndian.PutUint64(tsBytes[8*i:], uint64(ts))
+	}
+	if s.MillisecondTimestamps {
+		tsBytes = append([]byte{millisecondMarker}, tsBytes...)
+	} else if len(timestamps) == 1 {
+		// trim leading zeroes
+		tsBytes = bytes.TrimLeft(tsBytes, "\x00")
+	}
+
+	return base64Encode(tsBytes)
+}
+
+// decodeTimestamps reverses encodeTimestamps, failing if the timestamps were
+// not recorded with the signer's precision.
+func (s *TimestampSigner) decodeTimestamps(ts string) ([]int64, error) {
+	tsBytes, err := base64Decode(ts)
+	if err != nil {
+		return nil, err
+	}
+	if s.MillisecondTimestamps {
+		if len(tsBytes) < 9 || len(tsBytes)%8 != 1 || tsBytes[0] != millisecondMarker {
+			return nil, InvalidSignatureError{errors.New("timestamp does not have millisecond precision")}
+		}
+		tsBytes = tsBytes[1:]
+	} else if len(tsBytes) < 8 {
+		// left pad up to 8 bytes
 		tsBytes = append(
 			make([]byte, 8-len(tsBytes)),
 			tsBytes...,
 		)
 	}
+	if len(tsBytes)%8 != 0 {
+		return nil, InvalidSignatureError{errors.New("malformed timestamp")}
+	}
 
-	var timestamp = int64(binary.BigEndian.Uint64(tsBytes))
+	timestamps := make([]int64, len(tsBytes)/8)
+	for i := range timestamps {
+		timestamps[i] = int64(binary.BigEndian.Uint64(tsBytes[8*i:]))
+	}
+	return timestamps, nil
+}
+
+// checkTimestamp decodes the given timestamp and checks it against maxAge
+// and any recorded expiry and not-before time, returning the time of signing.
+// When a maxAge is given or an expiry is recorded, signing times more than
+// futureTolerance in the future are rejected, as their age would be negative.
+// Otherwise, as in Python itsdangerous, they are accepted.
+func (s *TimestampSigner) checkTimestamp(ts string, maxAge time.Duration) (time.Time, error) {
+	precision := s.precision()
+	if maxAge > 0 && maxAge < precision {
+		// Rather than rounding to a maxAge that would either never or
+		// always expire, refuse to check it at all.
+		return time.Time{}, fmt.Errorf("maxAge %s is shorter than the timestamp precision of %s", maxAge, precision)
+	}
+
+	timestamps, err := s.decodeTimestamps(ts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	now := s.toTimestamp(NowFunc())
+	// Compare in whole units of precision, as timestamps may be arbitrarily
+	// large and durations overflow.
+	age := subtract(now, timestamps[0])
+	// An expiry of zero means none was set.
+	expires := len(timestamps) > 1 && timestamps[1] != 0
 
-	if maxAge > 0 {
-		maxAgeSecs := int64(maxAge.Seconds())
-		if age := getTimestamp() - timestamp; age > maxAgeSecs {
-			return "", signatureExpired(age, maxAgeSecs)
+	if (maxAge > 0 || expires) && age < -int64(futureTolerance/precision) {
+		return time.Time{}, InvalidSignatureError{errors.New("signature timestamp is in the future")}
+	}
+	if maxAge > 0 && age > int64(maxAge/precision) {
+		return time.Time{}, signatureExpired(toDuration(age, precision), maxAge)
+	}
+	if expires {
+		if lifetime := subtract(timestamps[1], timestamps[0]); age > lifetime {
+			return time.Time{}, signatureExpired(toDuration(age, precision), toDuration(lifetime, precision))
+		}
+	}
+	if len(timestamps) > 2 && now < timestamps[2] {
+		return time.Time{}, notYetValid(s.fromTimestamp(timestamps[2]), s.fromTimestamp(now))
+	}
+	return s.fromTimestamp(timestamps[0]), nil
+}
+
+// subtract returns a - b, saturating rather than overflowing.
+func subtract(a, b int64) int64 {
+	d := a - b
+	if (a^b)&(a^d) < 0 {
+		if b < 0 {
+			return math.MaxInt64
 		}
+		return math.MinInt64
+	}
+	return d
+}
+
+// toDuration converts a number of units of precision to a duration,
+// saturating rather than overflowing.
+func toDuration(units int64, precision time.Duration) time.Duration {
+	switch {
+	case units > int64(math.MaxInt64/precision):
+		return math.MaxInt64
+	case units < int64(math.MinInt64/precision):
+		return math.MinInt64
 	}
-	return val, nil
+	return time.Duration(units) * precision
 }
@@ -164,3 +164,490 @@ func TestTimestampSignerUnsign(t *testing.T) {
 		})
 	}
 }
+
+func TestSignerDetached(t *testing.T) {
+	tests := []struct {
+		value       string
+		sig         string
+		expectError bool
+	}{
+		{value: "my string", sig: "xv0r21ogoygusbkJA01c4OxsAio"},
+		// separator in the value needs no escaping
+		{value: "my.string", sig: "6zK7GFxPLNKIqMsqZ2iMYW-q3pk"},
+		{value: "altered string", sig: "xv0r21ogoygusbkJA01c4OxsAio", expectError: true},
+		{value: "my string", sig: "not base64!", expectError: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.value, func(t *testing.T) {
+			sig := itsdangerous.NewSigner("secret_key", "salt")
+
+			err := sig.VerifyDetached(test.value, test.sig)
+			if test.expectError {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("VerifyDetached(%s, %s) expected InvalidSignatureError; got %v", test.value, test.sig, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("VerifyDetached(%s, %s) returned error: %s", test.value, test.sig, err)
+				}
+				if actual := sig.SignDetached(test.value); actual != test.sig {
+					t.Errorf("SignDetached(%s) got %s; want %s", test.value, actual, test.sig)
+				}
+			}
+		})
+	}
+}
+
+func TestTimestampSignerDetached(t *testing.T) {
+	tests := []struct {
+		value         string
+		sig           string
+		now           time.Time
+		maxAge        time.Duration
+		expectError   bool
+		expectExpired bool
+	}{
+		// Signature within maxAge
+		{value: "my string", sig: "Zva6YA.aqBNzGvNEDkO6RGFPEX1HIhz0vU",
+			now: time.Date(2024, 9, 27, 14, 4, 59, 0, time.UTC), maxAge: 5 * time.Minute},
+		// signature expired
+		{value: "my string", sig: "Zva6YA.aqBNzGvNEDkO6RGFPEX1HIhz0vU", expectError: true, expectExpired: true,
+			now: time.Date(2024, 9, 27, 14, 5, 1, 0, time.UTC), maxAge: 5 * time.Minute},
+		// altered value
+		{value: "my string!", sig: "Zva6YA.aqBNzGvNEDkO6RGFPEX1HIhz0vU", expectError: true,
+			now: time.Date(2024, 9, 27, 14, 4, 59, 0, time.UTC), maxAge: 5 * time.Minute},
+		// missing timestamp
+		{value: "my string", sig: "xv0r21ogoygusbkJA01c4OxsAio", expectError: true,
+			now: time.Date(2024, 9, 27, 14, 4, 59, 0, time.UTC), maxAge: 5 * time.Minute},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.sig, func(t *testing.T) {
+			if !test.now.IsZero() {
+				itsdangerous.NowFunc = func() time.Time { return test.now }
+				defer func() { itsdangerous.NowFunc = time.Now }()
+			}
+
+			sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+
+			err := sig.VerifyDetached(test.value, test.sig, test.maxAge)
+			if test.expectError {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("VerifyDetached(%s, %s) expected InvalidSignatureError; got %v", test.value, test.sig, err)
+				}
+				if test.expectExpired != errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+					t.Fatalf("VerifyDetached(%s, %s) expired = %t; got %v", test.value, test.sig, test.expectExpired, err)
+				}
+			} else if err != nil {
+				t.Fatalf("VerifyDetached(%s, %s) returned error: %s", test.value, test.sig, err)
+			}
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC) }
+		defer func() { itsdangerous.NowFunc = time.Now }()
+
+		sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+		detached := sig.SignDetached("my.string")
+		if expected := "Zva6YA."; detached[:len(expected)] != expected {
+			t.Errorf("SignDetached() got %s; want prefix %s", detached, expected)
+		}
+		if err := sig.VerifyDetached("my.string", detached, time.Minute); err != nil {
+			t.Errorf("VerifyDetached() returned error: %s", err)
+		}
+	})
+}
+
+func TestSignerSeparator(t *testing.T) {
+	tests := []struct {
+		sep         string
+		expectError bool
+	}{
+		// empty uses the default
+		{sep: ""},
+		{sep: "."},
+		{sep: ":"},
+		{sep: "|"},
+		{sep: "~"},
+		{sep: "::"},
+		// partially outside the alphabet so can't appear in a signature
+		{sep: "a."},
+		{sep: "A", expectError: true},
+		{sep: "Z", expectError: true},
+		{sep: "a", expectError: true},
+		{sep: "z", expectError: true},
+		{sep: "0", expectError: true},
+		{sep: "9", expectError: true},
+		{sep: "-", expectError: true},
+		{sep: "_", expectError: true},
+		{sep: "=", expectError: true},
+		{sep: "ab", expectError: true},
+		{sep: "-_", expectError: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.sep, func(t *testing.T) {
+			sig, err := itsdangerous.NewSignerWithOptions("secret_key", "salt", test.sep, "", nil, nil)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("NewSignerWithOptions(sep=%q) expected error; got no error", test.sep)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSignerWithOptions(sep=%q) returned error: %s", test.sep, err)
+			}
+
+			actual, err := sig.Unsign(sig.Sign("my string"))
+			if err != nil {
+				t.Fatalf("Unsign() returned error: %s", err)
+			}
+			if actual != "my string" {
+				t.Errorf("Unsign() got %s; want %s", actual, "my string")
+			}
+
+			tsSig, err := itsdangerous.NewTimestampSignerWithOptions("secret_key", "salt", test.sep, "", nil, nil)
+			if err != nil {
+				t.Fatalf("NewTimestampSignerWithOptions(sep=%q) returned error: %s", test.sep, err)
+			}
+			actual, err = tsSig.Unsign(tsSig.Sign("my string"), time.Minute)
+			if err != nil {
+				t.Fatalf("Unsign() returned error: %s", err)
+			}
+			if actual != "my string" {
+				t.Errorf("Unsign() got %s; want %s", actual, "my string")
+			}
+		})
+	}
+}
+
+func TestTimestampSignerSignWithExpiry(t *testing.T) {
+	itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC) }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+
+	actual := sig.SignWithExpiry("my string", time.Hour)
+	expected := "my string.AAAAAGb2umAAAAAAZvbIcA.w-ZGXK5zKfxAZo_oYlR3dBJaNlM"
+	if actual != expected {
+		t.Errorf("SignWithExpiry(my string) got %#v; want %#v", actual, expected)
+	}
+}
+
+func TestTimestampSignerUnsignWithExpiry(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		now           time.Time
+		maxAge        time.Duration
+		expectError   bool
+		expectExpired bool
+	}{
+		/* Signed at 2024-09-27T14:00:00Z with a ttl of 1 hour */
+		{name: "within expiry", input: "my string.AAAAAGb2umAAAAAAZvbIcA.w-ZGXK5zKfxAZo_oYlR3dBJaNlM",
+			now: time.Date(2024, 9, 27, 15, 0, 0, 0, time.UTC)},
+		{name: "expired", input: "my string.AAAAAGb2umAAAAAAZvbIcA.w-ZGXK5zKfxAZo_oYlR3dBJaNlM",
+			now: time.Date(2024, 9, 27, 15, 0, 1, 0, time.UTC), expectError: true, expectExpired: true},
+		{name: "expiry beyond maxAge", input: "my string.AAAAAGb2umAAAAAAZvbIcA.w-ZGXK5zKfxAZo_oYlR3dBJaNlM",
+			now: time.Date(2024, 9, 27, 14, 5, 1, 0, time.UTC), maxAge: 5 * time.Minute,
+			expectError: true, expectExpired: true},
+		{name: "maxAge beyond expiry", input: "my string.AAAAAGb2umAAAAAAZvbIcA.w-ZGXK5zKfxAZo_oYlR3dBJaNlM",
+			now: time.Date(2024, 9, 27, 15, 0, 1, 0, time.UTC), maxAge: 24 * time.Hour,
+			expectError: true, expectExpired: true},
+		// Plain timestamps are still accepted
+		{name: "no expiry", input: "my string.Zva6YA.aqBNzGvNEDkO6RGFPEX1HIhz0vU",
+			now: time.Date(2024, 9, 28, 14, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			itsdangerous.NowFunc = func() time.Time { return test.now }
+			defer func() { itsdangerous.NowFunc = time.Now }()
+
+			sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+
+			actual, err := sig.Unsign(test.input, test.maxAge)
+			if test.expectError {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("Unsign(%s) expected InvalidSignatureError; got %v", test.input, err)
+				}
+				if test.expectExpired != errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+					t.Fatalf("Unsign(%s) expired = %t; got %v", test.input, test.expectExpired, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unsign(%s) returned error: %s", test.input, err)
+				}
+				if actual != "my string" {
+					t.Errorf("Unsign(%s) got %#v; want %#v", test.input, actual, "my string")
+				}
+			}
+		})
+	}
+}
+
+func TestTimestampSignerSignWithNotBefore(t *testing.T) {
+	itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC) }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+	notBefore := time.Date(2024, 9, 28, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ttl      time.Duration
+		expected string
+	}{
+		{ttl: 0, expected: "my string.AAAAAGb2umAAAAAAAAAAAAAAAABm-Avg.rWo9IO-lkscwprnOmaSQaMWjSZw"},
+		{ttl: time.Hour, expected: "my string.AAAAAGb2umAAAAAAZvgZ8AAAAABm-Avg.zbqUiNm8Gfu0bCv7D8-jaEGQ9HY"},
+	}
+	for _, test := range tests {
+		actual := sig.SignWithNotBefore("my string", notBefore, test.ttl)
+		if actual != test.expected {
+			t.Errorf("SignWithNotBefore(my string, %s) got %#v; want %#v", test.ttl, actual, test.expected)
+		}
+	}
+}
+
+func TestTimestampSignerUnsignWithNotBefore(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		now            time.Time
+		maxAge         time.Duration
+		expectError    bool
+		expectNotValid bool
+		expectExpired  bool
+	}{
+		/* Signed at 2024-09-27T14:00:00Z, valid from 2024-09-28T14:00:00Z */
+		{name: "before", input: "my string.AAAAAGb2umAAAAAAAAAAAAAAAABm-Avg.rWo9IO-lkscwprnOmaSQaMWjSZw",
+			now: time.Date(2024, 9, 28, 13, 59, 59, 0, time.UTC), expectError: true, expectNotValid: true},
+		{name: "after", input: "my string.AAAAAGb2umAAAAAAAAAAAAAAAABm-Avg.rWo9IO-lkscwprnOmaSQaMWjSZw",
+			now: time.Date(2024, 9, 28, 14, 0, 0, 0, time.UTC)},
+		{name: "no expiry", input: "my string.AAAAAGb2umAAAAAAAAAAAAAAAABm-Avg.rWo9IO-lkscwprnOmaSQaMWjSZw",
+			now: time.Date(2025, 9, 28, 14, 0, 0, 0, time.UTC)},
+		{name: "maxAge from signing", input: "my string.AAAAAGb2umAAAAAAAAAAAAAAAABm-Avg.rWo9IO-lkscwprnOmaSQaMWjSZw",
+			now: time.Date(2024, 9, 28, 14, 0, 0, 0, time.UTC), maxAge: time.Hour, expectError: true, expectExpired: true},
+		/* As above, expiring an hour after becoming valid */
+		{name: "with ttl before", input: "my string.AAAAAGb2umAAAAAAZvgZ8AAAAABm-Avg.zbqUiNm8Gfu0bCv7D8-jaEGQ9HY",
+			now: time.Date(2024, 9, 28, 13, 59, 59, 0, time.UTC), expectError: true, expectNotValid: true},
+		{name: "with ttl valid", input: "my string.AAAAAGb2umAAAAAAZvgZ8AAAAABm-Avg.zbqUiNm8Gfu0bCv7D8-jaEGQ9HY",
+			now: time.Date(2024, 9, 28, 15, 0, 0, 0, time.UTC)},
+		{name: "with ttl expired", input: "my string.AAAAAGb2umAAAAAAZvgZ8AAAAABm-Avg.zbqUiNm8Gfu0bCv7D8-jaEGQ9HY",
+			now: time.Date(2024, 9, 28, 15, 0, 1, 0, time.UTC), expectError: true, expectExpired: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			itsdangerous.NowFunc = func() time.Time { return test.now }
+			defer func() { itsdangerous.NowFunc = time.Now }()
+
+			sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+
+			actual, err := sig.Unsign(test.input, test.maxAge)
+			if test.expectError {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("Unsign(%s) expected InvalidSignatureError; got %v", test.input, err)
+				}
+				var notValid itsdangerous.NotYetValidError
+				if test.expectNotValid != errors.As(err, &notValid) {
+					t.Fatalf("Unsign(%s) not yet valid = %t; got %v", test.input, test.expectNotValid, err)
+				}
+				if test.expectNotValid && !notValid.NotBefore().Equal(time.Date(2024, 9, 28, 14, 0, 0, 0, time.UTC)) {
+					t.Errorf("Unsign(%s) got NotBefore %s", test.input, notValid.NotBefore())
+				}
+				if test.expectExpired != errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+					t.Fatalf("Unsign(%s) expired = %t; got %v", test.input, test.expectExpired, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unsign(%s) returned error: %s", test.input, err)
+				}
+				if actual != "my string" {
+					t.Errorf("Unsign(%s) got %#v; want %#v", test.input, actual, "my string")
+				}
+			}
+		})
+	}
+}
+
+func TestTimestampSignerPrecision(t *testing.T) {
+	signedAt := time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		milliseconds  bool
+		elapsed       time.Duration
+		maxAge        time.Duration
+		expectError   bool
+		expectExpired bool
+	}{
+		{name: "ms within maxAge", milliseconds: true, elapsed: 500 * time.Millisecond, maxAge: 500 * time.Millisecond},
+		{name: "ms expired", milliseconds: true, elapsed: 501 * time.Millisecond, maxAge: 500 * time.Millisecond,
+			expectError: true, expectExpired: true},
+		{name: "ms long maxAge", milliseconds: true, elapsed: 5 * time.Minute, maxAge: 5 * time.Minute},
+		{name: "s fractional maxAge", elapsed: time.Second, maxAge: 1500 * time.Millisecond},
+		{name: "s fractional maxAge expired", elapsed: 2 * time.Second, maxAge: 1500 * time.Millisecond,
+			expectError: true, expectExpired: true},
+		// Can't be checked meaningfully with second precision
+		{name: "s sub-second maxAge", elapsed: 0, maxAge: 500 * time.Millisecond, expectError: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			itsdangerous.NowFunc = func() time.Time { return signedAt }
+			defer func() { itsdangerous.NowFunc = time.Now }()
+
+			sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+			sig.MillisecondTimestamps = test.milliseconds
+			signed := sig.Sign("my string")
+
+			itsdangerous.NowFunc = func() time.Time { return signedAt.Add(test.elapsed) }
+			actual, err := sig.Unsign(signed, test.maxAge)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("Unsign(%s) expected error; got no error", signed)
+				}
+				if test.expectExpired != errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+					t.Fatalf("Unsign(%s) expired = %t; got %v", signed, test.expectExpired, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unsign(%s) returned error: %s", signed, err)
+				}
+				if actual != "my string" {
+					t.Errorf("Unsign(%s) got %#v; want %#v", signed, actual, "my string")
+				}
+			}
+		})
+	}
+
+	t.Run("ms expiry", func(t *testing.T) {
+		itsdangerous.NowFunc = func() time.Time { return signedAt }
+		defer func() { itsdangerous.NowFunc = time.Now }()
+
+		sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+		sig.MillisecondTimestamps = true
+		signed := sig.SignWithExpiry("my string", 250*time.Millisecond)
+
+		itsdangerous.NowFunc = func() time.Time { return signedAt.Add(250 * time.Millisecond) }
+		if _, err := sig.Unsign(signed, 0); err != nil {
+			t.Errorf("Unsign(%s) returned error: %s", signed, err)
+		}
+		itsdangerous.NowFunc = func() time.Time { return signedAt.Add(251 * time.Millisecond) }
+		if _, err := sig.Unsign(signed, 0); !errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+			t.Errorf("Unsign(%s) expected SignatureExpiredError; got %v", signed, err)
+		}
+	})
+
+	// Signatures with one precision must not verify with the other, or their
+	// timestamps would be misread.
+	t.Run("mixed precision", func(t *testing.T) {
+		itsdangerous.NowFunc = func() time.Time { return signedAt }
+		defer func() { itsdangerous.NowFunc = time.Now }()
+
+		ms := itsdangerous.NewTimestampSigner("secret_key", "salt")
+		ms.MillisecondTimestamps = true
+		s := itsdangerous.NewTimestampSigner("secret_key", "salt")
+		msSigned := []string{ms.Sign("my string"), ms.SignWithExpiry("my string", time.Hour),
+			ms.SignWithNotBefore("my string", signedAt, time.Hour)}
+		sSigned := []string{s.Sign("my string"), s.SignWithExpiry("my string", time.Hour),
+			s.SignWithNotBefore("my string", signedAt, time.Hour)}
+
+		for _, elapsed := range []time.Duration{0, 30 * 24 * time.Hour} {
+			itsdangerous.NowFunc = func() time.Time { return signedAt.Add(elapsed) }
+			for _, signed := range msSigned {
+				if _, err := s.Unsign(signed, time.Minute); err == nil || errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+					t.Errorf("Unsign(%s) of millisecond signature after %s expected malformed timestamp error; got %v", signed, elapsed, err)
+				}
+			}
+			for _, signed := range sSigned {
+				if _, err := ms.Unsign(signed, time.Minute); err == nil || errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+					t.Errorf("Unsign(%s) of second signature after %s expected malformed timestamp error; got %v", signed, elapsed, err)
+				}
+			}
+		}
+	})
+
+	t.Run("future", func(t *testing.T) {
+		defer func() { itsdangerous.NowFunc = time.Now }()
+
+		sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+		for _, test := range []struct {
+			ahead       time.Duration
+			expectError bool
+		}{
+			{ahead: 30 * time.Second},
+			{ahead: time.Hour, expectError: true},
+			{ahead: 200 * 365 * 24 * time.Hour, expectError: true},
+		} {
+			itsdangerous.NowFunc = func() time.Time { return signedAt.Add(test.ahead) }
+			signed := sig.Sign("my string")
+			itsdangerous.NowFunc = func() time.Time { return signedAt }
+			if _, err := sig.Unsign(signed, time.Minute); (err != nil) != test.expectError {
+				t.Errorf("Unsign(%s) signed %s ahead got error %v; want error %t", signed, test.ahead, err, test.expectError)
+			}
+			// Without a maxAge the age is not checked, so neither is this.
+			if _, err := sig.Unsign(signed, 0); err != nil {
+				t.Errorf("Unsign(%s) signed %s ahead with no maxAge returned error: %s", signed, test.ahead, err)
+			}
+		}
+
+		// A recorded expiry is checked like a maxAge.
+		itsdangerous.NowFunc = func() time.Time { return signedAt.Add(time.Hour) }
+		signed := sig.SignWithExpiry("my string", 2*time.Hour)
+		itsdangerous.NowFunc = func() time.Time { return signedAt }
+		if _, err := sig.Unsign(signed, 0); err == nil {
+			t.Errorf("Unsign(%s) with expiry signed an hour ahead expected error", signed)
+		}
+	})
+
+	// An age too long to represent as a duration must not wrap around.
+	t.Run("ancient", func(t *testing.T) {
+		itsdangerous.NowFunc = func() time.Time { return time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC) }
+		defer func() { itsdangerous.NowFunc = time.Now }()
+
+		sig := itsdangerous.NewTimestampSigner("secret_key", "salt")
+		signed := sig.Sign("my string")
+		itsdangerous.NowFunc = func() time.Time { return signedAt }
+		if _, err := sig.Unsign(signed, time.Minute); !errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+			t.Errorf("Unsign(%s) expected SignatureExpiredError; got %v", signed, err)
+		}
+	})
+}
+
+func TestSignerKeyDerivation(t *testing.T) {
+	tests := []struct {
+		derivation  string
+		expected    string
+		expectError bool
+	}{
+		{derivation: "concat", expected: "my string.9kdknIv7LWbox-Hf2QREZJ13rTE"},
+		{derivation: "django-concat", expected: "my string.xv0r21ogoygusbkJA01c4OxsAio"},
+		{derivation: "hmac", expected: "my string.Q8ZXuvMJCFLTugCvWcfbJUM1EMk"},
+		{derivation: "none", expected: "my string.oolbhe8yXballsNy-1bwGYuEYec"},
+		{derivation: "hkdf", expected: "my string.FryT9YxlJWrG8nwfVRHDt5Pzzow"},
+		{derivation: "pbkdf2", expected: "my string.SqtmmAWix_xgv6nxIIa2IlE4H44"},
+		{derivation: "unknown", expectError: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.derivation, func(t *testing.T) {
+			sig, err := itsdangerous.NewSignerWithOptions("secret_key", "salt", "", test.derivation, nil, nil)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("NewSignerWithOptions(%s) expected error; got no error", test.derivation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSignerWithOptions(%s) returned error: %s", test.derivation, err)
+			}
+
+			actual := sig.Sign("my string")
+			if actual != test.expected {
+				t.Errorf("Sign(my string) got %s; want %s", actual, test.expected)
+			}
+		})
+	}
+}
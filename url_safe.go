@@ -3,9 +3,12 @@ package itsdangerous
 import (
 	"bytes"
 	"compress/zlib"
+	"context"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
+	"strings"
 	"time"
 )
 
@@ -18,17 +21,38 @@ func NewURLSafeSerializer(secret, salt string) *URLSafeSerializer {
 	return &URLSafeSerializer{Signer: *s}
 }
 
+// NewParentURLSafeSerializer creates a new URLSafeSerializer which keeps the
+// secret, so that WithSalt can create children. See NewParentSigner.
+func NewParentURLSafeSerializer(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm) (*URLSafeSerializer, error) {
+	s, err := NewParentSigner(secret, salt, sep, derivation, digest, algo)
+	if err != nil {
+		return nil, err
+	}
+	return &URLSafeSerializer{Signer: *s}, nil
+}
+
 func (s *URLSafeSerializer) Marshal(value interface{}) (string, error) {
+	return s.MarshalContext(context.Background(), value)
+}
+
+// MarshalContext works like Marshal but passes ctx to the signing backend.
+func (s *URLSafeSerializer) MarshalContext(ctx context.Context, value interface{}) (string, error) {
 	encoded, err := urlSafeSerialize(value)
 	if err != nil {
 		return "", err
 	}
 
-	return s.Signer.Sign(encoded), nil
+	return s.Signer.SignContext(ctx, encoded)
 }
 
 func (s *URLSafeSerializer) Unmarshal(signed string, value interface{}) error {
-	encoded, err := s.Signer.Unsign(signed)
+	return s.UnmarshalContext(context.Background(), signed, value)
+}
+
+// UnmarshalContext works like Unmarshal but passes ctx to the signing
+// backend.
+func (s *URLSafeSerializer) UnmarshalContext(ctx context.Context, signed string, value interface{}) error {
+	encoded, err := s.Signer.UnsignContext(ctx, signed)
 	if err != nil {
 		return err
 	}
@@ -38,6 +62,11 @@ func (s *URLSafeSerializer) Unmarshal(signed string, value interface{}) error {
 
 type URLSafeTimedSerializer struct {
 	TimestampSigner
+
+	// Purpose, if set, is embedded in every token and authenticated along
+	// with the payload. Unmarshal rejects tokens issued for any other
+	// purpose with a WrongPurposeError.
+	Purpose string
 }
 
 func NewURLSafeTimedSerializer(secret, salt string) *URLSafeTimedSerializer {
@@ -45,47 +74,173 @@ func NewURLSafeTimedSerializer(secret, salt string) *URLSafeTimedSerializer {
 	return &URLSafeTimedSerializer{TimestampSigner: *s}
 }
 
+// NewParentURLSafeTimedSerializer creates a new URLSafeTimedSerializer which
+// keeps the secret, so that WithSalt can create children. See
+// NewParentSigner.
+func NewParentURLSafeTimedSerializer(secret, salt, sep string, derivation KeyDerivation, digest func() hash.Hash, algo SigningAlgorithm) (*URLSafeTimedSerializer, error) {
+	s, err := NewParentTimestampSigner(secret, salt, sep, derivation, digest, algo)
+	if err != nil {
+		return nil, err
+	}
+	return &URLSafeTimedSerializer{TimestampSigner: *s}, nil
+}
+
 func (s *URLSafeTimedSerializer) Marshal(value interface{}) (string, error) {
-	encoded, err := urlSafeSerialize(value)
+	return s.MarshalContext(context.Background(), value)
+}
+
+// MarshalContext works like Marshal but passes ctx to the signing backend.
+func (s *URLSafeTimedSerializer) MarshalContext(ctx context.Context, value interface{}) (string, error) {
+	encoded, err := s.encode(value)
 	if err != nil {
 		return "", err
 	}
 
-	return s.TimestampSigner.Sign(encoded), nil
+	return s.TimestampSigner.SignContext(ctx, encoded)
 }
 
 func (s *URLSafeTimedSerializer) Unmarshal(signed string, value interface{}, maxAge time.Duration) error {
-	encoded, err := s.TimestampSigner.Unsign(signed, maxAge)
+	return s.UnmarshalContext(context.Background(), signed, value, maxAge)
+}
+
+// UnmarshalContext works like Unmarshal but passes ctx to the signing
+// backend.
+func (s *URLSafeTimedSerializer) UnmarshalContext(ctx context.Context, signed string, value interface{}, maxAge time.Duration) error {
+	encoded, err := s.TimestampSigner.UnsignContext(ctx, signed, maxAge)
 	if err != nil {
 		return err
 	}
 
-	return urlSafeDeserialize(encoded, value)
+	return s.decode(encoded, value)
 }
 
-func urlSafeSerialize(value interface{}) (string, error) {
-	jsonEncoded, err := json.Marshal(value)
+// MarshalWithExpiry works like Marshal but records in the token that it
+// expires ttl after signing. See TimestampSigner.SignWithExpiry.
+func (s *URLSafeTimedSerializer) MarshalWithExpiry(value interface{}, ttl time.Duration) (string, error) {
+	return s.MarshalWithExpiryContext(context.Background(), value, ttl)
+}
+
+// MarshalWithExpiryContext works like MarshalWithExpiry but passes ctx to the
+// signing backend.
+func (s *URLSafeTimedSerializer) MarshalWithExpiryContext(ctx context.Context, value interface{}, ttl time.Duration) (string, error) {
+	encoded, err := s.encode(value)
 	if err != nil {
-		return "", fmt.Errorf("error JSON marshalling payload: %w", err)
+		return "", err
 	}
 
-	compressed := false
-	var buf bytes.Buffer
-	zw := zlib.NewWriter(&buf)
-	_, err = zw.Write(jsonEncoded)
+	return s.SignWithExpiryContext(ctx, encoded, ttl)
+}
+
+// MarshalWithNotBefore works like Marshal but records in the token that it is
+// not valid until notBefore, optionally expiring ttl after that. See
+// TimestampSigner.SignWithNotBefore.
+func (s *URLSafeTimedSerializer) MarshalWithNotBefore(value interface{}, notBefore time.Time, ttl time.Duration) (string, error) {
+	return s.MarshalWithNotBeforeContext(context.Background(), value, notBefore, ttl)
+}
+
+// MarshalWithNotBeforeContext works like MarshalWithNotBefore but passes ctx
+// to the signing backend.
+func (s *URLSafeTimedSerializer) MarshalWithNotBeforeContext(ctx context.Context, value interface{}, notBefore time.Time, ttl time.Duration) (string, error) {
+	encoded, err := s.encode(value)
 	if err != nil {
-		return "", fmt.Errorf("error compressing payload: %w", err)
+		return "", err
 	}
-	err = zw.Close()
+
+	return s.SignWithNotBeforeContext(ctx, encoded, notBefore, ttl)
+}
+
+// MarshalBound works like Marshal but additionally binds the token to the
+// given data, such as a password hash or email address. The binding data is
+// mixed into the signature but not stored in the token, and the same data
+// must be passed to UnmarshalBound for the token to verify. This makes it
+// easy to issue tokens that stop working once the bound state changes.
+func (s *URLSafeTimedSerializer) MarshalBound(value interface{}, binding ...string) (string, error) {
+	return s.MarshalBoundContext(context.Background(), value, binding...)
+}
+
+// MarshalBoundContext works like MarshalBound but passes ctx to the signing
+// backend.
+func (s *URLSafeTimedSerializer) MarshalBoundContext(ctx context.Context, value interface{}, binding ...string) (string, error) {
+	b, err := s.bound(binding)
 	if err != nil {
-		return "", fmt.Errorf("error compressing payload: %w", err)
+		return "", err
 	}
-	if buf.Len() < len(jsonEncoded) {
-		jsonEncoded = buf.Bytes()
-		compressed = true
+	return b.MarshalContext(ctx, value)
+}
+
+// UnmarshalBound verifies a token created by MarshalBound with the same
+// binding data.
+func (s *URLSafeTimedSerializer) UnmarshalBound(signed string, value interface{}, maxAge time.Duration, binding ...string) error {
+	return s.UnmarshalBoundContext(context.Background(), signed, value, maxAge, binding...)
+}
+
+// UnmarshalBoundContext works like UnmarshalBound but passes ctx to the
+// signing backend.
+func (s *URLSafeTimedSerializer) UnmarshalBoundContext(ctx context.Context, signed string, value interface{}, maxAge time.Duration, binding ...string) error {
+	b, err := s.bound(binding)
+	if err != nil {
+		return err
+	}
+	return b.UnmarshalContext(ctx, signed, value, maxAge)
+}
+
+func (s URLSafeTimedSerializer) bound(binding []string) (*URLSafeTimedSerializer, error) {
+	signer, err := s.Signer.bound(binding)
+	if err != nil {
+		return nil, err
+	}
+	s.Signer = *signer
+	return &s, nil
+}
+
+// encode serializes value and appends the purpose, if any, ready for signing.
+func (s *URLSafeTimedSerializer) encode(value interface{}) (string, error) {
+	encoded, err := urlSafeSerialize(value)
+	if err != nil {
+		return "", err
+	}
+	if s.Purpose != "" {
+		encoded += s.sep + base64Encode([]byte(s.Purpose))
+	}
+	return encoded, nil
+}
+
+// decode checks the purpose of an unsigned token and deserializes it into
+// value.
+func (s *URLSafeTimedSerializer) decode(encoded string, value interface{}) error {
+	encoded, err := s.checkPurpose(encoded)
+	if err != nil {
+		return err
+	}
+
+	return urlSafeDeserialize(encoded, value)
+}
+
+// checkPurpose strips the purpose segment, if any, from an unsigned token and
+// checks it matches the serializer's purpose.
+func (s *URLSafeTimedSerializer) checkPurpose(encoded string) (string, error) {
+	var purpose string
+	// An index of zero is the compression marker rather than a separator.
+	if li := strings.LastIndex(encoded, s.sep); li > 0 {
+		decoded, err := base64Decode(encoded[li+len(s.sep):])
+		if err != nil {
+			return "", err
+		}
+		encoded, purpose = encoded[:li], string(decoded)
 	}
+	if purpose != s.Purpose {
+		return "", wrongPurpose(purpose, s.Purpose)
+	}
+	return encoded, nil
+}
 
-	encoded := base64Encode(jsonEncoded)
+func urlSafeSerialize(value interface{}) (string, error) {
+	payload, compressed, err := compressPayload(value)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64Encode(payload)
 	if compressed {
 		encoded = "." + encoded
 	}
@@ -105,22 +260,184 @@ func urlSafeDeserialize(encoded string, value interface{}) error {
 		return err
 	}
 
+	return decompressPayload(decoded, decompress, value)
+}
+
+// compressPayload JSON encodes value and compresses it if that makes it
+// shorter, reporting whether it was compressed.
+func compressPayload(value interface{}) ([]byte, bool, error) {
+	jsonEncoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("error JSON marshalling payload: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, err = zw.Write(jsonEncoded)
+	if err != nil {
+		return nil, false, fmt.Errorf("error compressing payload: %w", err)
+	}
+	err = zw.Close()
+	if err != nil {
+		return nil, false, fmt.Errorf("error compressing payload: %w", err)
+	}
+	if buf.Len() < len(jsonEncoded) {
+		return buf.Bytes(), true, nil
+	}
+
+	return jsonEncoded, false, nil
+}
+
+// decompressPayload reverses compressPayload, decoding the JSON into value.
+func decompressPayload(payload []byte, decompress bool, value interface{}) error {
 	if decompress {
-		zr, err := zlib.NewReader(bytes.NewReader(decoded))
+		zr, err := zlib.NewReader(bytes.NewReader(payload))
 		if err != nil {
 			return fmt.Errorf("Error decompressing payload: %w", err)
 		}
 		defer zr.Close()
-		decoded, err = io.ReadAll(zr)
+		payload, err = io.ReadAll(zr)
 		if err != nil {
 			return fmt.Errorf("Error decompressing payload: %w", err)
 		}
 	}
 
-	err = json.Unmarshal([]byte(decoded), value)
+	err := json.Unmarshal(payload, value)
 	if err != nil {
 		return fmt.Errorf("error JSON unmarshalling payload: %w", err)
 	}
 
 	return nil
 }
+
+// BackendURLSafeSerializer works like URLSafeSerializer but signs with a
+// SigningBackend which may fail. As with BackendSigner, it only has the
+// methods which take a context and return an error.
+type BackendURLSafeSerializer struct {
+	sep     string
+	backend SigningBackend
+
+	// RevocationChecker works as for Signer.
+	RevocationChecker RevocationChecker
+}
+
+// NewURLSafeSerializerWithBackend creates a new BackendURLSafeSerializer
+// which signs using the given backend.
+func NewURLSafeSerializerWithBackend(backend SigningBackend, sep string) (*BackendURLSafeSerializer, error) {
+	sep, err := checkSep(sep)
+	if err != nil {
+		return nil, err
+	}
+	return &BackendURLSafeSerializer{sep: sep, backend: backend}, nil
+}
+
+// NewURLSafeSerializerWithKeyring creates a new BackendURLSafeSerializer
+// which signs with the given keyring.
+func NewURLSafeSerializerWithKeyring(keyring *Keyring) *BackendURLSafeSerializer {
+	return &BackendURLSafeSerializer{sep: ".", backend: keyring}
+}
+
+func (s *BackendURLSafeSerializer) serializer() *URLSafeSerializer {
+	return &URLSafeSerializer{Signer: Signer{sep: s.sep, backend: s.backend, RevocationChecker: s.RevocationChecker}}
+}
+
+// MarshalContext serializes and signs the value, passing ctx to the signing
+// backend.
+func (s *BackendURLSafeSerializer) MarshalContext(ctx context.Context, value interface{}) (string, error) {
+	return s.serializer().MarshalContext(ctx, value)
+}
+
+// UnmarshalContext verifies and deserializes the token, passing ctx to the
+// signing backend.
+func (s *BackendURLSafeSerializer) UnmarshalContext(ctx context.Context, signed string, value interface{}) error {
+	return s.serializer().UnmarshalContext(ctx, signed, value)
+}
+
+// Fingerprint returns an identifier of the serializer's key. See
+// Signer.Fingerprint.
+func (s *BackendURLSafeSerializer) Fingerprint() string {
+	return s.serializer().Fingerprint()
+}
+
+// BackendURLSafeTimedSerializer works like URLSafeTimedSerializer but signs
+// with a SigningBackend which may fail. As with BackendSigner, it only has
+// the methods which take a context and return an error.
+type BackendURLSafeTimedSerializer struct {
+	sep     string
+	backend SigningBackend
+
+	// RevocationChecker, MillisecondTimestamps and Purpose work as for
+	// URLSafeTimedSerializer.
+	RevocationChecker     RevocationChecker
+	MillisecondTimestamps bool
+	Purpose               string
+}
+
+// NewURLSafeTimedSerializerWithBackend creates a new
+// BackendURLSafeTimedSerializer which signs using the given backend.
+func NewURLSafeTimedSerializerWithBackend(backend SigningBackend, sep string) (*BackendURLSafeTimedSerializer, error) {
+	sep, err := checkSep(sep)
+	if err != nil {
+		return nil, err
+	}
+	return &BackendURLSafeTimedSerializer{sep: sep, backend: backend}, nil
+}
+
+// NewURLSafeTimedSerializerWithKeyring creates a new
+// BackendURLSafeTimedSerializer which signs with the given keyring.
+func NewURLSafeTimedSerializerWithKeyring(keyring *Keyring) *BackendURLSafeTimedSerializer {
+	return &BackendURLSafeTimedSerializer{sep: ".", backend: keyring}
+}
+
+func (s *BackendURLSafeTimedSerializer) serializer() *URLSafeTimedSerializer {
+	return &URLSafeTimedSerializer{
+		TimestampSigner: TimestampSigner{
+			Signer:                Signer{sep: s.sep, backend: s.backend, RevocationChecker: s.RevocationChecker},
+			MillisecondTimestamps: s.MillisecondTimestamps,
+		},
+		Purpose: s.Purpose,
+	}
+}
+
+// MarshalContext serializes and signs the value, passing ctx to the signing
+// backend.
+func (s *BackendURLSafeTimedSerializer) MarshalContext(ctx context.Context, value interface{}) (string, error) {
+	return s.serializer().MarshalContext(ctx, value)
+}
+
+// UnmarshalContext verifies and deserializes the token, passing ctx to the
+// signing backend. See URLSafeTimedSerializer.Unmarshal.
+func (s *BackendURLSafeTimedSerializer) UnmarshalContext(ctx context.Context, signed string, value interface{}, maxAge time.Duration) error {
+	return s.serializer().UnmarshalContext(ctx, signed, value, maxAge)
+}
+
+// MarshalWithExpiryContext works like MarshalContext but records in the token
+// that it expires ttl after signing.
+func (s *BackendURLSafeTimedSerializer) MarshalWithExpiryContext(ctx context.Context, value interface{}, ttl time.Duration) (string, error) {
+	return s.serializer().MarshalWithExpiryContext(ctx, value, ttl)
+}
+
+// MarshalWithNotBeforeContext works like MarshalContext but records in the
+// token that it is not valid until notBefore, optionally expiring ttl after
+// that.
+func (s *BackendURLSafeTimedSerializer) MarshalWithNotBeforeContext(ctx context.Context, value interface{}, notBefore time.Time, ttl time.Duration) (string, error) {
+	return s.serializer().MarshalWithNotBeforeContext(ctx, value, notBefore, ttl)
+}
+
+// MarshalBoundContext works like MarshalContext but binds the token to the
+// given data. See URLSafeTimedSerializer.MarshalBound.
+func (s *BackendURLSafeTimedSerializer) MarshalBoundContext(ctx context.Context, value interface{}, binding ...string) (string, error) {
+	return s.serializer().MarshalBoundContext(ctx, value, binding...)
+}
+
+// UnmarshalBoundContext works like UnmarshalContext for tokens created by
+// MarshalBoundContext with the same binding data.
+func (s *BackendURLSafeTimedSerializer) UnmarshalBoundContext(ctx context.Context, signed string, value interface{}, maxAge time.Duration, binding ...string) error {
+	return s.serializer().UnmarshalBoundContext(ctx, signed, value, maxAge, binding...)
+}
+
+// Fingerprint returns an identifier of the serializer's key. See
+// Signer.Fingerprint.
+func (s *BackendURLSafeTimedSerializer) Fingerprint() string {
+	return s.serializer().Fingerprint()
+}
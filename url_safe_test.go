@@ -224,3 +224,147 @@ func TestURLSafeTimedSerializerUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestURLSafeTimedSerializerPurpose(t *testing.T) {
+	tests := []struct {
+		name          string
+		payload       interface{}
+		purpose       string
+		expectPurpose string
+		expectError   bool
+	}{
+		{name: "matching", payload: "my string", purpose: "reset", expectPurpose: "reset"},
+		{name: "compressed", payload: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", purpose: "reset", expectPurpose: "reset"},
+		{name: "no purpose", payload: "my string"},
+		{name: "wrong purpose", payload: "my string", purpose: "reset", expectPurpose: "invite", expectError: true},
+		{name: "unexpected purpose", payload: "my string", purpose: "reset", expectError: true},
+		{name: "missing purpose", payload: "my string", expectPurpose: "reset", expectError: true},
+		{name: "compressed missing purpose", payload: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", expectPurpose: "reset", expectError: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			sig := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+			sig.Purpose = test.purpose
+
+			signed, err := sig.Marshal(test.payload)
+			if err != nil {
+				t.Fatalf("Marshal returned error: %s", err)
+			}
+
+			sig.Purpose = test.expectPurpose
+			var actual interface{}
+			err = sig.Unmarshal(signed, &actual, time.Minute)
+			if test.expectError {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("Unmarshal(%s) expected InvalidSignatureError; got %v", signed, err)
+				}
+				if !errors.As(err, &itsdangerous.WrongPurposeError{}) {
+					t.Fatalf("Unmarshal(%s) expected WrongPurposeError; got %T(%s)", signed, err, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unmarshal(%s) returned error: %s", signed, err)
+				}
+				if !reflect.DeepEqual(actual, test.payload) {
+					t.Errorf("Unmarshal(%s) got %#v; want %#v", signed, actual, test.payload)
+				}
+			}
+		})
+	}
+}
+
+func TestURLSafeTimedSerializerBound(t *testing.T) {
+	tests := []struct {
+		name         string
+		binding      []string
+		checkBinding []string
+		expectError  bool
+	}{
+		{name: "matching", binding: []string{"pbkdf2:sha256$abc", "user@example.com"},
+			checkBinding: []string{"pbkdf2:sha256$abc", "user@example.com"}},
+		{name: "empty binding", binding: []string{""}, checkBinding: []string{""}},
+		{name: "changed", binding: []string{"pbkdf2:sha256$abc"}, checkBinding: []string{"pbkdf2:sha256$def"},
+			expectError: true},
+		{name: "missing", binding: []string{"pbkdf2:sha256$abc"}, expectError: true},
+		{name: "unexpected", checkBinding: []string{"pbkdf2:sha256$abc"}, expectError: true},
+		{name: "empty vs none", binding: []string{""}, expectError: true},
+		{name: "split differently", binding: []string{"ab", "c"}, checkBinding: []string{"a", "bc"},
+			expectError: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			sig := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+
+			signed, err := sig.MarshalBound("my string", test.binding...)
+			if err != nil {
+				t.Fatalf("MarshalBound returned error: %s", err)
+			}
+
+			var actual interface{}
+			err = sig.UnmarshalBound(signed, &actual, time.Minute, test.checkBinding...)
+			if test.expectError {
+				if !errors.As(err, &itsdangerous.InvalidSignatureError{}) {
+					t.Fatalf("UnmarshalBound(%s) expected InvalidSignatureError; got %v", signed, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("UnmarshalBound(%s) returned error: %s", signed, err)
+				}
+				if actual != "my string" {
+					t.Errorf("UnmarshalBound(%s) got %#v; want %#v", signed, actual, "my string")
+				}
+			}
+		})
+	}
+}
+
+func TestURLSafeTimedSerializerMarshalWithExpiry(t *testing.T) {
+	itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC) }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	sig := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+	reset, err := sig.MarshalWithExpiry("reset", time.Hour)
+	if err != nil {
+		t.Fatalf("MarshalWithExpiry returned error: %s", err)
+	}
+	invite, err := sig.MarshalWithExpiry("invite", 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("MarshalWithExpiry returned error: %s", err)
+	}
+
+	itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 16, 0, 0, 0, time.UTC) }
+	var actual interface{}
+	if err := sig.Unmarshal(reset, &actual, 0); !errors.As(err, &itsdangerous.SignatureExpiredError{}) {
+		t.Errorf("Unmarshal(%s) expected SignatureExpiredError; got %v", reset, err)
+	}
+	if err := sig.Unmarshal(invite, &actual, 0); err != nil {
+		t.Errorf("Unmarshal(%s) returned error: %s", invite, err)
+	} else if actual != "invite" {
+		t.Errorf("Unmarshal(%s) got %#v; want %#v", invite, actual, "invite")
+	}
+}
+
+func TestURLSafeTimedSerializerMarshalWithNotBefore(t *testing.T) {
+	itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 27, 14, 0, 0, 0, time.UTC) }
+	defer func() { itsdangerous.NowFunc = time.Now }()
+
+	sig := itsdangerous.NewURLSafeTimedSerializer("secret_key", "salt")
+	signed, err := sig.MarshalWithNotBefore("download", time.Date(2024, 9, 28, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("MarshalWithNotBefore returned error: %s", err)
+	}
+
+	var actual interface{}
+	if err := sig.Unmarshal(signed, &actual, 0); !errors.As(err, &itsdangerous.NotYetValidError{}) {
+		t.Errorf("Unmarshal(%s) expected NotYetValidError; got %v", signed, err)
+	}
+
+	itsdangerous.NowFunc = func() time.Time { return time.Date(2024, 9, 28, 0, 0, 0, 0, time.UTC) }
+	if err := sig.Unmarshal(signed, &actual, 0); err != nil {
+		t.Errorf("Unmarshal(%s) returned error: %s", signed, err)
+	} else if actual != "download" {
+		t.Errorf("Unmarshal(%s) got %#v; want %#v", signed, actual, "download")
+	}
+}